@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
 	"time"
 
+	"github.com/lwy110193/go_vendor/utils"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -21,24 +26,150 @@ type Cache interface {
 // ErrKeyNotFound 当键不存在时返回的错误
 var ErrKeyNotFound = errors.New("key not found")
 
+// ErrTimeout 缓存操作超时返回的错误
+var ErrTimeout = errors.New("cache operation timeout")
+
 // RedisCache 基于Redis的缓存实现
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
+	// 单次操作的默认超时时间，0表示不限制
+	timeout time.Duration
+	// GetOrSetDistributed使用的锁过期时间
+	lockTTL time.Duration
+	// Get/Set/Delete遇到瞬时错误时的最大执行次数及首次重试间隔
+	retryAttempts int
+	retryBackoff  time.Duration
+	// 所有键的统一前缀，用于多个应用共用同一Redis时隔离键空间
+	keyPrefix string
+	// 反序列化到interface{}时数字保留为json.Number
+	useNumber bool
+}
+
+// RedisCacheOption RedisCache配置选项
+type RedisCacheOption func(*RedisCache)
+
+// WithTimeout 设置单次操作的默认超时时间
+// 仅在调用方传入的ctx没有设置deadline时生效，调用方的deadline优先
+func WithTimeout(timeout time.Duration) RedisCacheOption {
+	return func(r *RedisCache) {
+		r.timeout = timeout
+	}
+}
+
+// WithLockTTL 设置GetOrSetDistributed的锁过期时间，默认10秒
+func WithLockTTL(ttl time.Duration) RedisCacheOption {
+	return func(r *RedisCache) {
+		r.lockTTL = ttl
+	}
+}
+
+// WithRetry 设置Get/Set/Delete遇到瞬时错误（如连接被重置）时的重试
+// attempts为最大执行次数（含首次），backoff为首次重试间隔，之后按指数增长
+// 键不存在、超时等错误不会重试；重试总耗时受单次操作超时限制
+func WithRetry(attempts int, backoff time.Duration) RedisCacheOption {
+	return func(r *RedisCache) {
+		r.retryAttempts = attempts
+		r.retryBackoff = backoff
+	}
+}
+
+// WithKeyPrefix 设置键前缀（如"myapp:"），读写时自动拼接，调用方仍使用不带前缀的键
+func WithKeyPrefix(prefix string) RedisCacheOption {
+	return func(r *RedisCache) {
+		r.keyPrefix = prefix
+	}
+}
+
+// WithUseNumber 读取时将interface{}类型目标中的数字解析为json.Number，避免大整数转为float64后丢失精度
+// 注意：开启后Get到interface{}或map[string]interface{}时，数字的类型由float64变为json.Number，
+// 调用方需通过Int64()/Float64()取值；解析到具体类型（如int64字段）的结构体不受影响
+func WithUseNumber() RedisCacheOption {
+	return func(r *RedisCache) {
+		r.useNumber = true
+	}
 }
 
 // NewRedisCache 创建Redis缓存实例
-func NewRedisCache(addr string, password string, db int) *RedisCache {
+func NewRedisCache(addr string, password string, db int, opts ...RedisCacheOption) *RedisCache {
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
 		DB:       db,
+		// 读写使用ctx的deadline，使操作超时能够及时返回
+		ContextTimeoutEnabled: true,
 	})
-	return &RedisCache{client: client}
+	return NewRedisCacheWithClient(client, opts...)
+}
+
+// NewRedisCacheWithTimeout 创建带默认操作超时的Redis缓存实例
+func NewRedisCacheWithTimeout(addr string, password string, db int, timeout time.Duration) *RedisCache {
+	return NewRedisCache(addr, password, db, WithTimeout(timeout))
+}
+
+// NewRedisCacheWithClient 使用已有的Redis客户端创建缓存实例
+// 如需超时生效于网络读写，客户端应开启ContextTimeoutEnabled
+func NewRedisCacheWithClient(client redis.UniversalClient, opts ...RedisCacheOption) *RedisCache {
+	r := &RedisCache{client: client, lockTTL: 10 * time.Second}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// withTimeout 调用方ctx未设置deadline时，按默认超时包装
+func (r *RedisCache) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.timeout)
 }
 
-// NewRedisClient 创建Redis客户端
-func NewRedisCacheWithClient(client *redis.Client) *RedisCache {
-	return &RedisCache{client: client}
+// redisKey 返回拼接前缀后的实际Redis键
+func (r *RedisCache) redisKey(key string) string {
+	return r.keyPrefix + key
+}
+
+// decode 按WithUseNumber的配置反序列化缓存数据
+func (r *RedisCache) decode(data []byte, dest interface{}) error {
+	return decodeJSON(data, dest, r.useNumber)
+}
+
+// wrapError 将超时错误转换为ErrTimeout
+func wrapError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return err
+}
+
+// isTransientError 判断是否为可重试的瞬时错误
+// redis.Nil、ctx取消、超时均不属于瞬时错误
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
+
+// retry 按WithRetry的配置执行fn，未配置时只执行一次
+func (r *RedisCache) retry(ctx context.Context, fn func() error) error {
+	return utils.Retry(ctx, r.retryAttempts, r.retryBackoff, isTransientError, fn)
 }
 
 // Set 设置缓存
@@ -48,35 +179,81 @@ func (r *RedisCache) Set(ctx context.Context, key string, value interface{}, exp
 	if err != nil {
 		return err
 	}
-	return r.client.Set(ctx, key, data, expiration).Err()
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return wrapError(ctx, r.retry(ctx, func() error {
+		return r.client.Set(ctx, r.redisKey(key), data, expiration).Err()
+	}))
 }
 
 // Get 获取缓存
 func (r *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
-	data, err := r.client.Get(ctx, key).Result()
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var data string
+	err := r.retry(ctx, func() (err error) {
+		data, err = r.client.Get(ctx, r.redisKey(key)).Result()
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return ErrKeyNotFound
 		}
+		return wrapError(ctx, err)
+	}
+	return r.decode([]byte(data), dest)
+}
+
+// GetAndTouch 获取缓存并将过期时间重置为ttl（GETEX），用于访问即续期的滑动过期场景
+// ttl<=0时按普通GET读取，不修改过期时间（GETEX的过期时间为0时会执行PERSIST，移除过期时间）
+func (r *RedisCache) GetAndTouch(ctx context.Context, key string, dest interface{}, ttl time.Duration) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var data string
+	err := r.retry(ctx, func() (err error) {
+		if ttl <= 0 {
+			data, err = r.client.Get(ctx, r.redisKey(key)).Result()
+		} else {
+			data, err = r.client.GetEx(ctx, r.redisKey(key), ttl).Result()
+		}
 		return err
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrKeyNotFound
+		}
+		return wrapError(ctx, err)
 	}
-	return json.Unmarshal([]byte(data), dest)
+	return r.decode([]byte(data), dest)
 }
 
 // Delete 删除缓存
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return wrapError(ctx, r.retry(ctx, func() error {
+		return r.client.Del(ctx, r.redisKey(key)).Err()
+	}))
 }
 
 // Exists 检查键是否存在
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
-	res, err := r.client.Exists(ctx, key).Result()
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	res, err := r.client.Exists(ctx, r.redisKey(key)).Result()
 	if err != nil {
-		return false, err
+		return false, wrapError(ctx, err)
 	}
 	return res > 0, nil
 }
 
+// Ping 检查Redis连接是否可用，错误类型见PingRedis
+func (r *RedisCache) Ping(ctx context.Context) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return PingRedis(ctx, r.client)
+}
+
 // Close 关闭Redis连接
 func (r *RedisCache) Close() error {
 	return r.client.Close()
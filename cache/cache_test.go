@@ -2,6 +2,10 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
 	"testing"
 	"time"
 
@@ -134,3 +138,238 @@ func TestRedisCacheComplexTypes(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, sliceValue, sliceResult)
 }
+
+// 测试Redis无响应时操作超时返回
+func TestRedisCacheTimeout(t *testing.T) {
+	// 模拟一个接受连接但从不响应的Redis
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cache := NewRedisCacheWithTimeout(listener.Addr().String(), "", 0, 200*time.Millisecond)
+	defer cache.Close()
+
+	start := time.Now()
+	var result string
+	err = cache.Get(context.Background(), "test_key", &result)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 调用方的deadline优先
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	err = cache.Set(ctx, "test_key", "value", time.Minute)
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+// 测试读取时续期
+func TestRedisCacheGetAndTouch(t *testing.T) {
+	client := newTestRedisClient(t)
+	cache := NewRedisCacheWithClient(client)
+	ctx := context.Background()
+
+	key := "test_get_and_touch"
+	defer cache.Delete(ctx, key)
+	assert.NoError(t, cache.Set(ctx, key, "value", 10*time.Second))
+
+	var result string
+	assert.NoError(t, cache.GetAndTouch(ctx, key, &result, time.Hour))
+	assert.Equal(t, "value", result)
+
+	ttl, err := client.TTL(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, 10*time.Second)
+
+	// ttl为0时不修改过期时间，不能变成永不过期
+	assert.NoError(t, cache.Set(ctx, key, "value", 10*time.Second))
+	assert.NoError(t, cache.GetAndTouch(ctx, key, &result, 0))
+	ttl, err = client.TTL(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, time.Duration(0))
+	assert.LessOrEqual(t, ttl, 10*time.Second)
+
+	assert.ErrorIs(t, cache.GetAndTouch(ctx, "test_get_and_touch_missing", &result, time.Hour), ErrKeyNotFound)
+}
+
+// closedAddr 返回一个当前没有监听的本地地址
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// 测试健康检查
+func TestRedisCachePing(t *testing.T) {
+	client := newTestRedisClient(t)
+	ctx := context.Background()
+	assert.NoError(t, NewRedisCacheWithClient(client).Ping(ctx))
+
+	// 密码错误
+	opts := client.Options()
+	badAuth := NewRedisCache(opts.Addr, "wrong_password", 0)
+	defer badAuth.Close()
+	assert.ErrorIs(t, badAuth.Ping(ctx), ErrRedisAuth)
+}
+
+// 测试地址不可达时的健康检查
+func TestRedisCachePingUnreachable(t *testing.T) {
+	cache := NewRedisCache(closedAddr(t), "", 0)
+	defer cache.Close()
+
+	err := cache.Ping(context.Background())
+	assert.ErrorIs(t, err, ErrRedisUnreachable)
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+// 测试键前缀
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	client := newTestRedisClient(t)
+	ctx := context.Background()
+	appA := NewRedisCacheWithClient(client, WithKeyPrefix("app_a:"))
+	appB := NewRedisCacheWithClient(client, WithKeyPrefix("app_b:"))
+
+	key := "test_key_prefix"
+	defer client.Del(ctx, "app_a:"+key, "app_b:"+key)
+
+	assert.NoError(t, appA.Set(ctx, key, "a", time.Minute))
+	raw, err := client.Get(ctx, "app_a:"+key).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, `"a"`, raw)
+	exists, err := client.Exists(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.Zero(t, exists)
+
+	// 不同前缀之间互不可见
+	var result string
+	assert.ErrorIs(t, appB.Get(ctx, key, &result), ErrKeyNotFound)
+	assert.NoError(t, appB.Set(ctx, key, "b", time.Minute))
+	assert.NoError(t, appA.Get(ctx, key, &result))
+	assert.Equal(t, "a", result)
+
+	ok, err := appA.Exists(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, appA.Delete(ctx, key))
+	ok, err = appB.Exists(ctx, key)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// 测试值未变化时不写入
+func TestRedisCacheSetIfChanged(t *testing.T) {
+	client := newTestRedisClient(t)
+	cache := NewRedisCacheWithClient(client)
+	ctx := context.Background()
+
+	key := "test_set_if_changed"
+	defer cache.Delete(ctx, key)
+	assert.NoError(t, cache.Delete(ctx, key))
+
+	changed, err := cache.SetIfChanged(ctx, key, "value", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// 相同的值不写入：以永不过期再次设置，原有TTL仍然保留
+	changed, err = cache.SetIfChanged(ctx, key, "value", 0)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	ttl, err := client.TTL(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.Greater(t, ttl, time.Minute)
+
+	changed, err = cache.SetIfChanged(ctx, key, "other", 0)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	var result string
+	assert.NoError(t, cache.Get(ctx, key, &result))
+	assert.Equal(t, "other", result)
+	ttl, err = client.TTL(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+
+	// 不足1ms的过期时间按1ms处理，不能变成永不过期
+	changed, err = cache.SetIfChanged(ctx, key, "short", 500*time.Microsecond)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	ttl, err = client.PTTL(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.NotEqual(t, time.Duration(-1), ttl)
+}
+
+// 测试WithUseNumber保留大整数精度
+func TestRedisCacheUseNumber(t *testing.T) {
+	client := newTestRedisClient(t)
+	cache := NewRedisCacheWithClient(client, WithUseNumber())
+	ctx := context.Background()
+
+	key := "test_use_number"
+	defer cache.Delete(ctx, key)
+
+	var id int64 = 1<<62 + 1
+	assert.NoError(t, cache.Set(ctx, key, map[string]interface{}{"id": id, "score": 1.5}, time.Minute))
+
+	var result map[string]interface{}
+	assert.NoError(t, cache.Get(ctx, key, &result))
+	assert.Equal(t, json.Number(strconv.FormatInt(id, 10)), result["id"])
+	got, err := result["id"].(json.Number).Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+	assert.Equal(t, json.Number("1.5"), result["score"])
+
+	// 批量读取同样生效
+	dest := make(map[string]map[string]interface{})
+	assert.NoError(t, GetMultiOrLoad(ctx, cache, []string{key}, dest, time.Minute,
+		func(ctx context.Context, missing []string) (map[string]map[string]interface{}, error) {
+			return nil, nil
+		}))
+	assert.Equal(t, json.Number(strconv.FormatInt(id, 10)), dest[key]["id"])
+}
+
+// 测试ClearPrefix只删除指定前缀下的键，且按WithKeyPrefix隔离
+func TestRedisCacheClearPrefix(t *testing.T) {
+	client := newTestRedisClient(t)
+	ctx := context.Background()
+	cache := NewRedisCacheWithClient(client, WithKeyPrefix("clear_test:"))
+	other := NewRedisCacheWithClient(client, WithKeyPrefix("clear_other:"))
+	defer client.Del(ctx, "clear_other:user:1")
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("user:%d", i), i, time.Minute))
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("order:%d", i), i, time.Minute))
+		defer cache.Delete(ctx, fmt.Sprintf("order:%d", i))
+	}
+	assert.NoError(t, other.Set(ctx, "user:1", 1, time.Minute))
+	// 前缀中的通配符按字面匹配
+	assert.NoError(t, cache.Set(ctx, "user*x", 0, time.Minute))
+	defer cache.Delete(ctx, "user*x")
+
+	n, err := cache.ClearPrefix(ctx, "user:")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	var result int
+	assert.ErrorIs(t, cache.Get(ctx, "user:1", &result), ErrKeyNotFound)
+	assert.NoError(t, cache.Get(ctx, "order:1", &result))
+	assert.Equal(t, 1, result)
+	assert.NoError(t, cache.Get(ctx, "user*x", &result))
+	assert.NoError(t, other.Get(ctx, "user:1", &result))
+
+	_, err = NewRedisCacheWithClient(client).ClearPrefix(ctx, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// setIfChangedScript 仅当当前值与新值不同时才写入，返回1表示已写入
+// ARGV[1]: 序列化后的新值；ARGV[2]: 过期毫秒数，<=0表示永不过期
+var setIfChangedScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return 0
+end
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// SetIfChanged 仅当序列化后的新值与缓存中的值不同时才写入，比较在Redis端通过Lua脚本原子完成
+// 值相同时不写入，也不会刷新原有的过期时间
+// 返回值: 是否发生了写入
+func (r *RedisCache) SetIfChanged(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var res int64
+	err = r.retry(ctx, func() (err error) {
+		res, err = setIfChangedScript.Run(ctx, r.client, []string{r.redisKey(key)}, data, expirationMs(expiration)).Int64()
+		return err
+	})
+	if err != nil {
+		return false, wrapError(ctx, err)
+	}
+	return res == 1, nil
+}
+
+// expirationMs 将过期时间转换为毫秒数，不足1ms的正数按1ms处理，避免被截断为0变成永不过期
+func expirationMs(expiration time.Duration) int64 {
+	if expiration > 0 && expiration < time.Millisecond {
+		return 1
+	}
+	return expiration.Milliseconds()
+}
+
+// SetIfChanged 仅当序列化后的新值与缓存中未过期的值不同时才写入
+// 值相同时不写入，也不会刷新原有的过期时间；expiration的含义同Set
+// 返回值: 是否发生了写入
+func (m *MemoryCache) SetIfChanged(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if item, found := m.items[key]; found && (item.expiration.IsZero() || time.Now().Before(item.expiration)) && bytes.Equal(item.value, data) {
+		return false, nil
+	}
+	m.items[key] = &memoryItem{
+		value:      data,
+		expiration: m.expiry(expiration),
+	}
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// clearPrefixScanCount ClearPrefix每次SCAN建议返回的键数量
+const clearPrefixScanCount = 500
+
+// globEscaper 转义SCAN MATCH模式中的通配符，使前缀按字面匹配
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// ClearPrefix 删除所有以prefix开头的键，返回删除的数量；prefix不含WithKeyPrefix设置的前缀
+// 通过SCAN逐批查找并删除，不会像KEYS那样阻塞Redis；执行期间新写入的键可能不会被删除
+// 集群模式下依次在每个主节点上执行。未设置键前缀时prefix不能为空，避免误删整个库
+func (r *RedisCache) ClearPrefix(ctx context.Context, prefix string) (int64, error) {
+	pattern := r.redisKey(prefix)
+	if pattern == "" {
+		return 0, errors.New("prefix must not be empty")
+	}
+	pattern = globEscaper.Replace(pattern) + "*"
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		n, err := scanAndDelete(ctx, r.client, pattern)
+		return n, wrapError(ctx, err)
+	}
+
+	// ForEachMaster并发调用回调，删除数量需原子累加
+	var total int64
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		n, err := scanAndDelete(ctx, node, pattern)
+		atomic.AddInt64(&total, n)
+		return err
+	})
+	return atomic.LoadInt64(&total), wrapError(ctx, err)
+}
+
+// scanAndDelete 在单个节点上SCAN匹配pattern的键并逐批删除
+// 每批键通过pipeline逐个DEL，避免集群模式下多键DEL跨槽报错
+func scanAndDelete(ctx context.Context, client redis.UniversalClient, pattern string) (int64, error) {
+	var (
+		cursor uint64
+		total  int64
+	)
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, clearPrefixScanCount).Result()
+		if err != nil {
+			return total, err
+		}
+		if len(keys) > 0 {
+			cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range keys {
+					pipe.Del(ctx, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return total, err
+			}
+			for _, cmd := range cmds {
+				total += cmd.(*redis.IntCmd).Val()
+			}
+		}
+		if next == 0 {
+			return total, nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// decodeJSON 将缓存数据反序列化到dest
+// useNumber为true时，interface{}类型的目标（包括map[string]interface{}中的值）中的数字解析为json.Number而不是float64，
+// 超过2^53的int64（如雪花ID）不会丢失精度
+func decodeJSON(data []byte, dest interface{}, useNumber bool) error {
+	if !useNumber {
+		return json.Unmarshal(data, dest)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(dest)
+}
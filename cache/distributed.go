@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrWaitTimeout 等待其他实例重建缓存超时返回的错误
+var ErrWaitTimeout = errors.New("wait for cache rebuild timeout")
+
+// distributedPollInterval 未获取到锁时轮询缓存的间隔
+var distributedPollInterval = 50 * time.Millisecond
+
+// GetOrSetDistributed 获取缓存，不存在时由多个实例中的一个调用loader重建
+// key: 缓存键，锁的键名为 前缀 + key + ":lock"
+// dest: 结果指针
+// ttl: 缓存过期时间
+// loader: 加载数据的函数
+//
+// 获取到锁的实例执行loader并写入缓存；其他实例每隔50ms轮询缓存，
+// 最长等待锁的过期时间(WithLockTTL，默认10秒)，超时返回ErrWaitTimeout，ctx取消时返回ctx错误。
+// loader执行时间应小于锁的过期时间，否则锁过期后可能有其他实例重复执行loader
+func (r *RedisCache) GetOrSetDistributed(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) error {
+	err := r.Get(ctx, key, dest)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+
+	lock := NewRedisLock(r.client, r.redisKey(key)+":lock", r.lockTTL)
+	locked, err := lock.TryLock(ctx)
+	if err != nil {
+		return wrapError(ctx, err)
+	}
+	if locked {
+		defer lock.Unlock(context.WithoutCancel(ctx))
+		// 获取锁期间其他实例可能已完成重建
+		if err := r.Get(ctx, key, dest); !errors.Is(err, ErrKeyNotFound) {
+			return err
+		}
+		value, err := loader(ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.Set(ctx, key, value, ttl); err != nil {
+			return err
+		}
+		return r.Get(ctx, key, dest)
+	}
+
+	// 未获取到锁，等待其他实例重建
+	timer := time.NewTimer(r.lockTTL)
+	defer timer.Stop()
+	ticker := time.NewTicker(distributedPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return fmt.Errorf("%w: %s", ErrWaitTimeout, key)
+		case <-ticker.C:
+			if err := r.Get(ctx, key, dest); !errors.Is(err, ErrKeyNotFound) {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试分布式锁的获取与释放
+func TestRedisLock(t *testing.T) {
+	client := newTestRedisClient(t)
+	ctx := context.Background()
+	key := "test:lock:basic"
+	client.Del(ctx, key)
+
+	a := NewRedisLock(client, key, time.Second)
+	b := NewRedisLock(client, key, time.Second)
+
+	ok, err := a.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = b.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// 不能释放其他实例持有的锁
+	assert.ErrorIs(t, b.Unlock(ctx), ErrLockNotHeld)
+	assert.NoError(t, a.Unlock(ctx))
+
+	ok, err = b.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, b.Unlock(ctx))
+}
+
+// 测试多个实例并发重建缓存时loader只执行一次
+func TestGetOrSetDistributed(t *testing.T) {
+	base := newTestRedisClient(t)
+	ctx := context.Background()
+	key := "test:distributed:rebuild"
+	base.Del(ctx, key, key+":lock")
+
+	// 模拟两个实例，各自使用独立的连接
+	newInstance := func() *RedisCache {
+		client := redis.NewClient(base.Options())
+		t.Cleanup(func() { client.Close() })
+		return NewRedisCacheWithClient(client, WithLockTTL(2*time.Second))
+	}
+	instances := []*RedisCache{newInstance(), newInstance()}
+
+	var calls int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(200 * time.Millisecond)
+		return "rebuilt", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+	errs := make([]error, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = instances[i%2].GetOrSetDistributed(ctx, key, &results[i], time.Minute, loader)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for i := range results {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, "rebuilt", results[i])
+	}
+	base.Del(ctx, key)
+}
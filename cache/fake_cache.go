@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// FakeCache 用于测试的内存缓存，实现Cache接口
+// 与MemoryCache不同，FakeCache不依赖真实时间，过期需通过Expire手动触发，
+// 并记录命中/未命中次数，便于在单元测试中断言缓存行为
+type FakeCache struct {
+	mutex sync.Mutex
+	// 缓存值，已序列化
+	items map[string][]byte
+	// Set时传入的过期时间
+	ttls map[string]time.Duration
+	// 命中次数
+	hits int
+	// 未命中次数
+	misses int
+	// 是否已关闭
+	closed bool
+}
+
+// NewFakeCache 创建测试用缓存实例
+func NewFakeCache() *FakeCache {
+	return &FakeCache{
+		items: make(map[string][]byte),
+		ttls:  make(map[string]time.Duration),
+	}
+}
+
+// Set 设置缓存
+func (f *FakeCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.items[key] = data
+	f.ttls[key] = expiration
+	return nil
+}
+
+// Get 获取缓存，键不存在时返回ErrKeyNotFound
+func (f *FakeCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	f.mutex.Lock()
+	data, found := f.items[key]
+	if found {
+		f.hits++
+	} else {
+		f.misses++
+	}
+	f.mutex.Unlock()
+
+	if !found {
+		return ErrKeyNotFound
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Delete 删除缓存
+func (f *FakeCache) Delete(ctx context.Context, key string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	f.Expire(key)
+	return nil
+}
+
+// Exists 检查键是否存在
+func (f *FakeCache) Exists(ctx context.Context, key string) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, found := f.items[key]
+	return found, nil
+}
+
+// Close 关闭缓存
+func (f *FakeCache) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.closed = true
+	return nil
+}
+
+// Expire 模拟键过期
+func (f *FakeCache) Expire(key string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.items, key)
+	delete(f.ttls, key)
+}
+
+// TTL 获取Set时传入的过期时间
+func (f *FakeCache) TTL(key string) (time.Duration, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	ttl, found := f.ttls[key]
+	return ttl, found
+}
+
+// Hits 获取命中次数
+func (f *FakeCache) Hits() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.hits
+}
+
+// Misses 获取未命中次数
+func (f *FakeCache) Misses() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.misses
+}
+
+// Closed 是否已调用Close
+func (f *FakeCache) Closed() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.closed
+}
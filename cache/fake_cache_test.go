@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFakeCacheMissAndHit 测试FakeCache的未命中与命中
+func TestFakeCacheMissAndHit(t *testing.T) {
+	var cache Cache = NewFakeCache()
+	fake := cache.(*FakeCache)
+	ctx := context.Background()
+
+	// 未命中
+	var result string
+	err := cache.Get(ctx, "user:1", &result)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, 1, fake.Misses())
+
+	// 写入后命中
+	assert.NoError(t, cache.Set(ctx, "user:1", "alice", time.Minute))
+	assert.NoError(t, cache.Get(ctx, "user:1", &result))
+	assert.Equal(t, "alice", result)
+	assert.Equal(t, 1, fake.Hits())
+
+	ttl, ok := fake.TTL("user:1")
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, ttl)
+
+	// 手动过期
+	fake.Expire("user:1")
+	exists, err := cache.Exists(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, cache.Close())
+	assert.True(t, fake.Closed())
+}
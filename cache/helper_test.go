@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisClient 创建测试用Redis客户端，地址可通过REDIS_ADDR环境变量指定
+// Redis不可用时跳过测试
+func newTestRedisClient(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "192.168.3.42:6379"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: "redis_MK8zA6",
+		DB:       0,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis %s not available: %v", addr, err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
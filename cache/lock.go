@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLockNotHeld 释放未持有的锁时返回的错误
+var ErrLockNotHeld = errors.New("lock not held")
+
+// unlockScript 仅当锁的值与token一致时才删除，避免误删其他实例的锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLock 基于Redis SET NX的分布式锁
+// 锁带有过期时间，持有者异常退出时锁会自动释放
+type RedisLock struct {
+	client redis.UniversalClient
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+// NewRedisLock 创建分布式锁
+// key: 锁的键名
+// ttl: 锁的过期时间，应大于持有锁期间的最长执行时间
+func NewRedisLock(client redis.UniversalClient, key string, ttl time.Duration) *RedisLock {
+	return &RedisLock{
+		client: client,
+		key:    key,
+		token:  newLockToken(),
+		ttl:    ttl,
+	}
+}
+
+// newLockToken 生成随机的锁标识
+func newLockToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// TryLock 尝试获取锁，不阻塞
+// 返回值: 是否获取成功
+func (l *RedisLock) TryLock(ctx context.Context) (bool, error) {
+	return l.client.SetNX(ctx, l.key, l.token, l.ttl).Result()
+}
+
+// Unlock 释放锁，锁已过期或被其他实例持有时返回ErrLockNotHeld
+func (l *RedisLock) Unlock(ctx context.Context) error {
+	res, err := unlockScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,8 +18,26 @@ type MemoryCache struct {
 	cleanupTicker *time.Ticker
 	// 停止清理的通道
 	stopChan chan struct{}
+	// 默认过期时间，Set传入0时使用，0表示未设置
+	defaultTTL time.Duration
+	// 反序列化到interface{}时数字保留为json.Number
+	useNumber bool
 }
 
+// MemoryCacheOption MemoryCache配置选项
+type MemoryCacheOption func(*MemoryCache)
+
+// WithMemoryUseNumber 读取时将interface{}类型目标中的数字解析为json.Number而不是float64，
+// 行为与RedisCache的WithUseNumber一致
+func WithMemoryUseNumber() MemoryCacheOption {
+	return func(m *MemoryCache) {
+		m.useNumber = true
+	}
+}
+
+// NoExpiration 永不过期，用于在设置了默认过期时间的MemoryCache中写入不过期的缓存项
+const NoExpiration time.Duration = -1
+
 // memoryItem 内存缓存项
 type memoryItem struct {
 	// 缓存值，已序列化
@@ -28,11 +47,14 @@ type memoryItem struct {
 }
 
 // NewMemoryCache 创建内存缓存实例
-func NewMemoryCache() *MemoryCache {
+func NewMemoryCache(opts ...MemoryCacheOption) *MemoryCache {
 	cache := &MemoryCache{
 		items:    make(map[string]*memoryItem),
 		stopChan: make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(cache)
+	}
 
 	// 启动清理过期项的后台协程
 	cache.startCleanupRoutine()
@@ -40,6 +62,15 @@ func NewMemoryCache() *MemoryCache {
 	return cache
 }
 
+// NewMemoryCacheWithOptions 创建带默认过期时间的内存缓存实例
+// 与NewMemoryCache不同，Set传入的expiration为0时使用defaultTTL，而不是永不过期；
+// 需要永不过期时传入NoExpiration。defaultTTL<=0时与NewMemoryCache行为一致
+func NewMemoryCacheWithOptions(defaultTTL time.Duration, opts ...MemoryCacheOption) *MemoryCache {
+	cache := NewMemoryCache(opts...)
+	cache.defaultTTL = defaultTTL
+	return cache
+}
+
 // startCleanupRoutine 启动清理过期项的后台协程
 func (m *MemoryCache) startCleanupRoutine() {
 	// 每5分钟清理一次过期项
@@ -50,7 +81,7 @@ func (m *MemoryCache) startCleanupRoutine() {
 			select {
 			case <- m.cleanupTicker.C:
 				m.deleteExpired()
-			case <- m.stopChan:
+			case <-m.stopChan:
 				m.cleanupTicker.Stop()
 				return
 			}
@@ -72,6 +103,7 @@ func (m *MemoryCache) deleteExpired() {
 }
 
 // Set 设置缓存
+// expiration>0时按该时长过期；为0时使用默认过期时间（未设置则永不过期）；为负数（NoExpiration）时永不过期
 func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	// 检查上下文是否已取消
 	if ctx.Err() != nil {
@@ -84,20 +116,35 @@ func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ex
 		return err
 	}
 
-	// 计算过期时间
-	var expiry time.Time
-	if expiration > 0 {
-		expiry = time.Now().Add(expiration)
+	m.mutex.Lock()
+	m.items[key] = &memoryItem{
+		value:      data,
+		expiration: m.expiry(expiration),
 	}
+	m.mutex.Unlock()
+
+	return nil
+}
 
+// setRaw 写入已序列化的数据，过期规则与Set相同
+func (m *MemoryCache) setRaw(key string, data []byte, expiration time.Duration) {
 	m.mutex.Lock()
 	m.items[key] = &memoryItem{
 		value:      data,
-		expiration: expiry,
+		expiration: m.expiry(expiration),
 	}
 	m.mutex.Unlock()
+}
 
-	return nil
+// expiry 按Set的过期规则计算过期时刻，零值表示永不过期
+func (m *MemoryCache) expiry(expiration time.Duration) time.Time {
+	if expiration == 0 {
+		expiration = m.defaultTTL
+	}
+	if expiration > 0 {
+		return time.Now().Add(expiration)
+	}
+	return time.Time{}
 }
 
 // Get 获取缓存
@@ -123,7 +170,36 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 	}
 
 	// 反序列化数据
-	return json.Unmarshal(item.value, dest)
+	return decodeJSON(item.value, dest, m.useNumber)
+}
+
+// GetAndTouch 获取缓存，命中时将过期时间重置为ttl，用于访问即续期的滑动过期场景
+// ttl<=0时不修改过期时间
+func (m *MemoryCache) GetAndTouch(ctx context.Context, key string, dest interface{}, ttl time.Duration) error {
+	// 检查上下文是否已取消
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	m.mutex.Lock()
+	item, found := m.items[key]
+	if !found || (!item.expiration.IsZero() && now.After(item.expiration)) {
+		// 过期项直接删除
+		if found {
+			delete(m.items, key)
+		}
+		m.mutex.Unlock()
+		return ErrKeyNotFound
+	}
+	if ttl > 0 {
+		item.expiration = now.Add(ttl)
+	}
+	value := item.value
+	m.mutex.Unlock()
+
+	// 反序列化数据
+	return decodeJSON(value, dest, m.useNumber)
 }
 
 // Delete 删除缓存
@@ -187,3 +263,15 @@ func (m *MemoryCache) Clear() {
 	m.items = make(map[string]*memoryItem)
 	m.mutex.Unlock()
 }
+
+// ClearPrefix 删除所有以prefix开头的缓存项，用于多个逻辑命名空间共用同一缓存时只清空其中一个
+// prefix为空时等同于Clear
+func (m *MemoryCache) ClearPrefix(prefix string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.items, key)
+		}
+	}
+}
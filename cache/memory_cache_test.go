@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"testing"
@@ -283,3 +284,118 @@ func TestMemoryCacheConcurrency(t *testing.T) {
 
 	assert.Empty(t, errors, "并发操作应该没有错误")
 }
+
+// 测试默认过期时间：expiration为0时使用默认值，NoExpiration永不过期
+func TestMemoryCacheDefaultTTL(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(50 * time.Millisecond)
+	defer cache.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, cache.Set(ctx, "default", "v", 0))
+	assert.NoError(t, cache.Set(ctx, "forever", "v", NoExpiration))
+	assert.NoError(t, cache.Set(ctx, "explicit", "v", time.Hour))
+
+	time.Sleep(100 * time.Millisecond)
+
+	var result string
+	assert.ErrorIs(t, cache.Get(ctx, "default", &result), ErrKeyNotFound)
+	assert.NoError(t, cache.Get(ctx, "forever", &result))
+	assert.NoError(t, cache.Get(ctx, "explicit", &result))
+
+	// 旧构造函数保持原行为：expiration为0时永不过期
+	legacy := NewMemoryCache()
+	defer legacy.Close()
+	assert.NoError(t, legacy.Set(ctx, "k", "v", 0))
+	legacy.mutex.RLock()
+	assert.True(t, legacy.items["k"].expiration.IsZero())
+	legacy.mutex.RUnlock()
+}
+
+// 测试读取时续期
+func TestMemoryCacheGetAndTouch(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, cache.Set(ctx, "key", "value", 50*time.Millisecond))
+
+	// 过期前多次读取并续期，总时长超过原过期时间后依然存在
+	var result string
+	for i := 0; i < 4; i++ {
+		time.Sleep(30 * time.Millisecond)
+		assert.NoError(t, cache.GetAndTouch(ctx, "key", &result, 50*time.Millisecond))
+		assert.Equal(t, "value", result)
+	}
+
+	// 不再访问后按最后一次续期的时间过期
+	time.Sleep(80 * time.Millisecond)
+	assert.ErrorIs(t, cache.GetAndTouch(ctx, "key", &result, 50*time.Millisecond), ErrKeyNotFound)
+	assert.ErrorIs(t, cache.GetAndTouch(ctx, "missing", &result, time.Second), ErrKeyNotFound)
+}
+
+// 测试值未变化时不写入
+func TestMemoryCacheSetIfChanged(t *testing.T) {
+	cache := NewMemoryCache()
+	defer cache.Close()
+	ctx := context.Background()
+
+	changed, err := cache.SetIfChanged(ctx, "key", map[string]int{"a": 1}, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	expiration := cache.items["key"].expiration
+
+	// 相同的值不写入，过期时间保持不变
+	changed, err = cache.SetIfChanged(ctx, "key", map[string]int{"a": 1}, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, expiration, cache.items["key"].expiration)
+
+	changed, err = cache.SetIfChanged(ctx, "key", map[string]int{"a": 2}, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	var result map[string]int
+	assert.NoError(t, cache.Get(ctx, "key", &result))
+	assert.Equal(t, 2, result["a"])
+}
+
+// 测试WithMemoryUseNumber保留大整数精度，未开启时仍解析为float64
+func TestMemoryCacheUseNumber(t *testing.T) {
+	ctx := context.Background()
+	var id int64 = 1<<62 + 1
+	value := map[string]interface{}{"id": id}
+
+	cache := NewMemoryCache(WithMemoryUseNumber())
+	defer cache.Close()
+	assert.NoError(t, cache.Set(ctx, "id", value, time.Hour))
+	var result map[string]interface{}
+	assert.NoError(t, cache.Get(ctx, "id", &result))
+	got, err := result["id"].(json.Number).Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	plain := NewMemoryCache()
+	defer plain.Close()
+	assert.NoError(t, plain.Set(ctx, "id", value, time.Hour))
+	result = nil
+	assert.NoError(t, plain.Get(ctx, "id", &result))
+	assert.IsType(t, float64(0), result["id"])
+}
+
+// 测试ClearPrefix只清空指定前缀下的缓存项
+func TestMemoryCacheClearPrefix(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache()
+	defer cache.Close()
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("user:%d", i), i, time.Hour))
+		assert.NoError(t, cache.Set(ctx, fmt.Sprintf("order:%d", i), i, time.Hour))
+	}
+
+	cache.ClearPrefix("user:")
+	assert.Equal(t, 3, cache.Size())
+	var result int
+	assert.ErrorIs(t, cache.Get(ctx, "user:1", &result), ErrKeyNotFound)
+	assert.NoError(t, cache.Get(ctx, "order:1", &result))
+	assert.Equal(t, 1, result)
+}
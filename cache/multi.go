@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// multiGetter 支持一次往返批量读取的缓存实现，GetMultiOrLoad优先使用
+type multiGetter interface {
+	getMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+	decode(data []byte, dest interface{}) error
+}
+
+// GetMultiOrLoad 批量获取缓存，未命中的键统一交给loader加载一次，写入缓存后与命中结果合并到dest
+// keys: 缓存键，重复的键只读取一次
+// dest: 结果map，命中及加载到的键写入其中
+// ttl: 加载结果的缓存过期时间
+// loader: 加载函数，只接收未命中的键；所有键都命中时不调用。返回map中没有的键视为不存在，不写入dest
+//
+// RedisCache通过pipeline一次往返读取，其他实现逐个调用Get。
+func GetMultiOrLoad[T any](ctx context.Context, c Cache, keys []string, dest map[string]T, ttl time.Duration, loader func(ctx context.Context, missing []string) (map[string]T, error)) error {
+	keys = uniqueKeys(keys)
+	if len(keys) == 0 {
+		return nil
+	}
+	missing, err := getMulti(ctx, c, keys, dest)
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	loaded, err := loader(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for _, key := range missing {
+		value, ok := loaded[key]
+		if !ok {
+			continue
+		}
+		if err := c.Set(ctx, key, value, ttl); err != nil {
+			return err
+		}
+		dest[key] = value
+	}
+	return nil
+}
+
+// getMulti 读取keys写入dest，返回未命中的键
+func getMulti[T any](ctx context.Context, c Cache, keys []string, dest map[string]T) ([]string, error) {
+	var missing []string
+	if mg, ok := c.(multiGetter); ok {
+		raw, err := mg.getMulti(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			data, ok := raw[key]
+			if !ok {
+				missing = append(missing, key)
+				continue
+			}
+			var value T
+			if err := mg.decode(data, &value); err != nil {
+				return nil, err
+			}
+			dest[key] = value
+		}
+		return missing, nil
+	}
+
+	for _, key := range keys {
+		var value T
+		err := c.Get(ctx, key, &value)
+		if errors.Is(err, ErrKeyNotFound) {
+			missing = append(missing, key)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dest[key] = value
+	}
+	return missing, nil
+}
+
+// uniqueKeys 去除重复的键，保持原有顺序
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, key)
+	}
+	return result
+}
+
+// getMulti 通过pipeline一次往返读取多个键，返回命中的原始数据
+// 不使用MGET，集群模式下键可以分布在不同slot
+func (r *RedisCache) getMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result := make(map[string][]byte, len(keys))
+	err := r.retry(ctx, func() error {
+		cmds := make([]*redis.StringCmd, len(keys))
+		_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				cmds[i] = pipe.Get(ctx, r.redisKey(key))
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, cmd := range cmds {
+			data, err := cmd.Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			result[keys[i]] = data
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+	return result, nil
+}
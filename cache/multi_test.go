@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type multiUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// testGetMultiOrLoad 验证loader只收到未命中的键，结果合并且加载结果写入缓存
+func testGetMultiOrLoad(t *testing.T, c Cache, prefix string) {
+	ctx := context.Background()
+	key := func(id string) string { return prefix + id }
+	require.NoError(t, c.Set(ctx, key("1"), multiUser{ID: "1", Name: "cached"}, time.Minute))
+	require.NoError(t, c.Set(ctx, key("3"), multiUser{ID: "3", Name: "cached"}, time.Minute))
+
+	var calls [][]string
+	loader := func(ctx context.Context, missing []string) (map[string]multiUser, error) {
+		calls = append(calls, missing)
+		result := make(map[string]multiUser)
+		for _, k := range missing {
+			// 4号用户不存在
+			if k != key("4") {
+				result[k] = multiUser{ID: k, Name: "loaded"}
+			}
+		}
+		return result, nil
+	}
+
+	dest := make(map[string]multiUser)
+	keys := []string{key("1"), key("2"), key("3"), key("4"), key("2")}
+	require.NoError(t, GetMultiOrLoad(ctx, c, keys, dest, time.Minute, loader))
+	assert.Equal(t, [][]string{{key("2"), key("4")}}, calls)
+	assert.Equal(t, map[string]multiUser{
+		key("1"): {ID: "1", Name: "cached"},
+		key("2"): {ID: key("2"), Name: "loaded"},
+		key("3"): {ID: "3", Name: "cached"},
+	}, dest)
+
+	// 加载结果已写入缓存，再次获取时只有不存在的键交给loader
+	calls = nil
+	dest = make(map[string]multiUser)
+	require.NoError(t, GetMultiOrLoad(ctx, c, keys, dest, time.Minute, loader))
+	assert.Equal(t, [][]string{{key("4")}}, calls)
+	assert.Len(t, dest, 3)
+
+	// 全部命中时不调用loader
+	calls = nil
+	require.NoError(t, GetMultiOrLoad(ctx, c, []string{key("1"), key("2")}, dest, time.Minute, loader))
+	assert.Empty(t, calls)
+
+	// loader错误原样返回
+	loadErr := errors.New("db down")
+	err := GetMultiOrLoad(ctx, c, []string{key("5")}, dest, time.Minute, func(ctx context.Context, missing []string) (map[string]multiUser, error) {
+		return nil, loadErr
+	})
+	assert.ErrorIs(t, err, loadErr)
+}
+
+// TestGetMultiOrLoadMemory 测试基于MemoryCache的批量加载
+func TestGetMultiOrLoadMemory(t *testing.T) {
+	c := NewMemoryCache()
+	defer c.Close()
+	testGetMultiOrLoad(t, c, "user:")
+}
+
+// TestGetMultiOrLoadRedis 测试基于RedisCache pipeline的批量加载
+func TestGetMultiOrLoadRedis(t *testing.T) {
+	client := newTestRedisClient(t)
+	c := NewRedisCacheWithClient(client, WithKeyPrefix("test_multi:"))
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		defer c.Delete(ctx, fmt.Sprintf("user:%d", i))
+	}
+	testGetMultiOrLoad(t, c, "user:")
+}
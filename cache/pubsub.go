@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSub 基于Redis的发布订阅封装，消息体使用JSON编码
+type PubSub struct {
+	client redis.UniversalClient
+}
+
+// InvalidationEvent 缓存失效事件
+type InvalidationEvent struct {
+	// Source 发布者标识，订阅方可据此忽略自己发出的事件
+	Source string `json:"source"`
+	// Keys 失效的缓存键
+	Keys []string `json:"keys"`
+}
+
+// NewPubSub 创建发布订阅实例
+func NewPubSub(client redis.UniversalClient) *PubSub {
+	return &PubSub{client: client}
+}
+
+// Publish 发布消息，v会被序列化为JSON
+func (p *PubSub) Publish(ctx context.Context, channel string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, channel, data).Err()
+}
+
+// Subscribe 订阅频道，阻塞接收消息直到ctx取消
+// 每条消息的原始内容交给handler处理，handler在接收循环中同步执行
+// ctx取消时返回nil，订阅失败或连接关闭时返回错误
+func (p *PubSub) Subscribe(ctx context.Context, channel string, handler func([]byte)) error {
+	sub, err := p.subscribe(ctx, channel)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	return receiveLoop(ctx, sub, handler)
+}
+
+// subscribe 订阅频道并等待订阅确认
+func (p *PubSub) subscribe(ctx context.Context, channel string) (*redis.PubSub, error) {
+	sub := p.client.Subscribe(ctx, channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// receiveLoop 接收消息直到ctx取消，退出时关闭订阅
+func receiveLoop(ctx context.Context, sub *redis.PubSub, handler func([]byte)) error {
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return errors.New("pubsub channel closed")
+			}
+			handler([]byte(msg.Payload))
+		}
+	}
+}
+
+// PublishInvalidation 广播缓存失效事件
+func (p *PubSub) PublishInvalidation(ctx context.Context, channel string, event InvalidationEvent) error {
+	return p.Publish(ctx, channel, event)
+}
+
+// SubscribeInvalidation 订阅缓存失效事件，阻塞直到ctx取消
+// 无法解析的消息会被忽略
+func (p *PubSub) SubscribeInvalidation(ctx context.Context, channel string, handler func(InvalidationEvent)) error {
+	return p.Subscribe(ctx, channel, func(data []byte) {
+		var event InvalidationEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return
+		}
+		handler(event)
+	})
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试发布订阅消息
+func TestPubSubPublishSubscribe(t *testing.T) {
+	client := newTestRedisClient(t)
+	ps := NewPubSub(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan InvalidationEvent, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- ps.SubscribeInvalidation(ctx, "test:pubsub:invalidate", func(event InvalidationEvent) {
+			select {
+			case received <- event:
+			default:
+			}
+		})
+	}()
+
+	// 订阅建立需要时间，重复发布直到收到消息
+	event := InvalidationEvent{Source: "node-1", Keys: []string{"user:1", "user:2"}}
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(3 * time.Second)
+	var got InvalidationEvent
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			assert.NoError(t, ps.PublishInvalidation(ctx, "test:pubsub:invalidate", event))
+		case got = <-received:
+			break loop
+		case <-timeout:
+			t.Fatal("timeout waiting for message")
+		}
+	}
+	assert.Equal(t, event, got)
+
+	// 取消后订阅循环退出
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("subscribe did not return after cancel")
+	}
+}
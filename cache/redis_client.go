@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOptions Redis客户端配置，支持单机、哨兵与集群部署
+// 部署模式：设置MasterName为哨兵模式；Cluster为true或Addrs多于一个为集群模式；否则为单机模式
+type RedisOptions struct {
+	// Addrs 地址列表：单机模式取第一个，哨兵模式为哨兵地址，集群模式为种子节点地址
+	Addrs []string `yaml:"addrs"`
+	// Username ACL用户名，可选
+	Username string `yaml:"username"`
+	// Password 密码
+	Password string `yaml:"password"`
+	// DB 数据库编号，集群模式下不生效
+	DB int `yaml:"db"`
+	// MasterName 哨兵模式的主节点名称
+	MasterName string `yaml:"master_name"`
+	// SentinelPassword 哨兵节点的密码，可选
+	SentinelPassword string `yaml:"sentinel_password"`
+	// Cluster 是否为集群模式，只配置一个地址（如云厂商的集群配置端点）时需显式设置
+	Cluster bool `yaml:"cluster"`
+	// TLSConfig TLS配置，不为nil时使用TLS连接
+	TLSConfig *tls.Config `yaml:"-"`
+	// DialTimeout 建立连接超时时间，0使用go-redis默认值
+	DialTimeout time.Duration `yaml:"dial_timeout"`
+	// ReadTimeout 读超时时间，0使用go-redis默认值
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+	// WriteTimeout 写超时时间，0使用go-redis默认值
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// PoolSize 连接池大小，0使用go-redis默认值
+	PoolSize int `yaml:"pool_size"`
+}
+
+// NewRedisClientWithOptions 按配置创建Redis客户端
+// 返回的UniversalClient可直接用于NewRedisCacheWithClient、NewPubSub、NewRedisLock及limiter.NewRedisBucket
+func NewRedisClientWithOptions(opts RedisOptions) redis.UniversalClient {
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		DB:               opts.DB,
+		MasterName:       opts.MasterName,
+		SentinelPassword: opts.SentinelPassword,
+		IsClusterMode:    opts.Cluster,
+		TLSConfig:        opts.TLSConfig,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
+		PoolSize:         opts.PoolSize,
+		// 读写使用ctx的deadline，与NewRedisCache一致
+		ContextTimeoutEnabled: true,
+	})
+}
+
+// ErrRedisAuth Redis认证失败（密码或ACL用户名错误、未提供密码）
+var ErrRedisAuth = errors.New("redis authentication failed")
+
+// ErrRedisUnreachable Redis不可达（连接被拒绝、地址无法解析等）
+var ErrRedisUnreachable = errors.New("redis unreachable")
+
+// PingRedis 检查Redis连接是否可用，用于启动自检与健康检查
+// 返回的错误可通过errors.Is区分ErrRedisAuth、ErrRedisUnreachable与ErrTimeout
+func PingRedis(ctx context.Context, client redis.UniversalClient) error {
+	err := client.Ping(ctx).Err()
+	if err == nil {
+		return nil
+	}
+	return describePingError(ctx, err)
+}
+
+// describePingError 将Ping错误转换为便于定位问题的错误
+func describePingError(ctx context.Context, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "NOAUTH") || strings.HasPrefix(msg, "WRONGPASS") ||
+		strings.Contains(msg, "invalid password") || strings.Contains(msg, "invalid username-password"):
+		return fmt.Errorf("%w: check username/password: %v", ErrRedisAuth, err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("%w: connection refused, check address and that redis is running: %v", ErrRedisUnreachable, err)
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return fmt.Errorf("%w: cannot resolve host: %v", ErrRedisUnreachable, err)
+	}
+	if wrapped := wrapError(ctx, err); wrapped != err {
+		return wrapped
+	}
+	return fmt.Errorf("redis ping failed: %w", err)
+}
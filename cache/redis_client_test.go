@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTLSRedisStub 启动只支持TLS的Redis桩服务：HELLO返回错误（使客户端回退到RESP2），其他命令返回OK
+// 返回监听地址、服务端证书以及完成TLS握手的连接数
+func startTLSRedisStub(t *testing.T) (string, *x509.Certificate, *atomic.Int32) {
+	// 借用httptest的自签名证书
+	certServer := httptest.NewTLSServer(nil)
+	t.Cleanup(certServer.Close)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certServer.TLS.Certificates})
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	var handshakes atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				if err := conn.(*tls.Conn).Handshake(); err != nil {
+					return
+				}
+				handshakes.Add(1)
+
+				reader := bufio.NewReader(conn)
+				for {
+					args, err := readRESPArray(reader)
+					if err != nil {
+						return
+					}
+					if strings.EqualFold(args[0], "hello") {
+						fmt.Fprint(conn, "-ERR unknown command 'HELLO'\r\n")
+						continue
+					}
+					fmt.Fprint(conn, "+OK\r\n")
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String(), certServer.Certificate(), &handshakes
+}
+
+// readRESPArray 读取一个RESP数组形式的命令
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err = r.ReadString('\n'); err != nil { // $len
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+	return args, nil
+}
+
+// 测试TLS配置生效：客户端通过TLS连接桩服务并完成命令
+func TestNewRedisClientWithOptionsTLS(t *testing.T) {
+	addr, cert, handshakes := startTLSRedisStub(t)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	tlsConfig := &tls.Config{RootCAs: roots, ServerName: "example.com", MinVersion: tls.VersionTLS12}
+
+	client := NewRedisClientWithOptions(RedisOptions{
+		Addrs:       []string{addr},
+		Password:    "secret",
+		DB:          2,
+		TLSConfig:   tlsConfig,
+		DialTimeout: time.Second,
+		PoolSize:    3,
+	})
+	defer client.Close()
+
+	simple, ok := client.(*redis.Client)
+	require.True(t, ok, "single address should build a simple client")
+	opts := simple.Options()
+	assert.Equal(t, addr, opts.Addr)
+	assert.Equal(t, "secret", opts.Password)
+	assert.Equal(t, 2, opts.DB)
+	assert.Same(t, tlsConfig, opts.TLSConfig)
+	assert.Equal(t, time.Second, opts.DialTimeout)
+	assert.Equal(t, 3, opts.PoolSize)
+	assert.True(t, opts.ContextTimeoutEnabled)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Ping(ctx).Err())
+	assert.GreaterOrEqual(t, handshakes.Load(), int32(1))
+
+	// 缓存可直接使用UniversalClient
+	cache := NewRedisCacheWithClient(client)
+	assert.NoError(t, cache.Set(ctx, "k", "v", time.Minute))
+}
+
+// 测试部署模式选择
+func TestNewRedisClientWithOptionsMode(t *testing.T) {
+	sentinel := NewRedisClientWithOptions(RedisOptions{Addrs: []string{"127.0.0.1:26379"}, MasterName: "mymaster"})
+	defer sentinel.Close()
+	_, ok := sentinel.(*redis.Client)
+	assert.True(t, ok, "sentinel mode should build a failover client")
+
+	cluster := NewRedisClientWithOptions(RedisOptions{Addrs: []string{"127.0.0.1:7000"}, Cluster: true})
+	defer cluster.Close()
+	_, ok = cluster.(*redis.ClusterClient)
+	assert.True(t, ok, "cluster mode should build a cluster client")
+
+	multi := NewRedisClientWithOptions(RedisOptions{Addrs: []string{"127.0.0.1:7000", "127.0.0.1:7001"}})
+	defer multi.Close()
+	_, ok = multi.(*redis.ClusterClient)
+	assert.True(t, ok, "multiple addresses should build a cluster client")
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyHook 模拟Redis服务：前failures次命令返回连接重置错误，之后在内存中执行GET/SET/DEL
+type flakyHook struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[string]int
+	data     map[string]string
+}
+
+func newFlakyHook(failures int) *flakyHook {
+	return &flakyHook{failures: failures, calls: map[string]int{}, data: map[string]string{}}
+}
+
+func (h *flakyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *flakyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		h.calls[cmd.Name()]++
+		if h.failures > 0 {
+			h.failures--
+			return &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+		}
+
+		args := cmd.Args()
+		switch c := cmd.(type) {
+		case *redis.StatusCmd: // SET
+			h.data[args[1].(string)] = string(args[2].([]byte))
+			c.SetVal("OK")
+		case *redis.StringCmd: // GET
+			val, ok := h.data[args[1].(string)]
+			if !ok {
+				return redis.Nil
+			}
+			c.SetVal(val)
+		case *redis.IntCmd: // DEL
+			delete(h.data, args[1].(string))
+			c.SetVal(1)
+		}
+		return nil
+	}
+}
+
+func (h *flakyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (h *flakyHook) count(name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls[name]
+}
+
+func newFlakyCache(failures int, opts ...RedisCacheOption) (*RedisCache, *flakyHook) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	hook := newFlakyHook(failures)
+	client.AddHook(hook)
+	return NewRedisCacheWithClient(client, opts...), hook
+}
+
+// 测试瞬时错误重试后成功
+func TestRedisCacheRetryTransientError(t *testing.T) {
+	ctx := context.Background()
+
+	cache, hook := newFlakyCache(1, WithRetry(3, time.Millisecond))
+	assert.NoError(t, cache.Set(ctx, "k", "v", time.Minute))
+	assert.Equal(t, 2, hook.count("set"))
+
+	hook.failures = 1
+	var got string
+	assert.NoError(t, cache.Get(ctx, "k", &got))
+	assert.Equal(t, "v", got)
+	assert.Equal(t, 2, hook.count("get"))
+
+	hook.failures = 1
+	assert.NoError(t, cache.Delete(ctx, "k"))
+	assert.Equal(t, 2, hook.count("del"))
+}
+
+// 测试键不存在不重试，未配置重试时瞬时错误直接返回
+func TestRedisCacheRetrySkipsKeyNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	cache, hook := newFlakyCache(0, WithRetry(3, time.Millisecond))
+	var got string
+	assert.ErrorIs(t, cache.Get(ctx, "missing", &got), ErrKeyNotFound)
+	assert.Equal(t, 1, hook.count("get"))
+
+	cache, hook = newFlakyCache(1)
+	assert.ErrorIs(t, cache.Set(ctx, "k", "v", time.Minute), syscall.ECONNRESET)
+	assert.Equal(t, 1, hook.count("set"))
+}
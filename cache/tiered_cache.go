@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// TieredCache 二级缓存，L1为进程内MemoryCache，L2为共享缓存(通常为RedisCache)
+// 读取时优先读L1，未命中时读L2并按L1TTL回填L1；写入与删除同时作用于两级缓存
+//
+// 多实例部署时，可通过EnableInvalidation订阅失效频道：
+// 任一实例Set/Delete后广播失效事件，其他实例收到后删除本地L1中的键。
+// 广播是异步的，从L2更新到其他实例收到事件之间存在短暂的不一致窗口
+// （通常为毫秒级，取决于Redis网络延迟），期间其他实例可能读到旧的L1数据；
+// 若订阅连接中断，则最长不一致时间为L1的过期时间，因此L1TTL不宜设置过长
+type TieredCache struct {
+	l1    *MemoryCache
+	l2    Cache
+	l1TTL time.Duration
+
+	// 失效广播相关
+	pubsub  *PubSub
+	channel string
+	source  string
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewTieredCache 创建二级缓存实例
+// l1: 进程内缓存
+// l2: 共享缓存
+// l1TTL: L1缓存的最长过期时间，<=0时与写入时的过期时间一致；
+// 此时无法确定从L2读到的数据何时过期，Get不会回填L1
+func NewTieredCache(l1 *MemoryCache, l2 Cache, l1TTL time.Duration) *TieredCache {
+	return &TieredCache{
+		l1:     l1,
+		l2:     l2,
+		l1TTL:  l1TTL,
+		source: fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// EnableInvalidation 开启失效广播，订阅成功后返回
+// 之后本实例的Set/Delete会向channel发布失效事件，并在收到其他实例的事件时删除L1中的键
+func (t *TieredCache) EnableInvalidation(ctx context.Context, pubsub *PubSub, channel string) error {
+	if t.cancel != nil {
+		return errors.New("invalidation already enabled")
+	}
+	sub, err := pubsub.subscribe(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	t.pubsub = pubsub
+	t.channel = channel
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		receiveLoop(loopCtx, sub, func(data []byte) {
+			var event InvalidationEvent
+			if err := json.Unmarshal(data, &event); err != nil || event.Source == t.source {
+				return
+			}
+			for _, key := range event.Keys {
+				t.l1.Delete(loopCtx, key)
+			}
+		})
+	}()
+	return nil
+}
+
+// l1Expiration 计算L1的过期时间
+func (t *TieredCache) l1Expiration(expiration time.Duration) time.Duration {
+	if t.l1TTL > 0 && (expiration <= 0 || expiration > t.l1TTL) {
+		return t.l1TTL
+	}
+	return expiration
+}
+
+// publishInvalidation 广播失效事件，未开启时不做处理
+func (t *TieredCache) publishInvalidation(ctx context.Context, key string) error {
+	if t.pubsub == nil {
+		return nil
+	}
+	return t.pubsub.PublishInvalidation(ctx, t.channel, InvalidationEvent{Source: t.source, Keys: []string{key}})
+}
+
+// Set 设置缓存，先写L2再写L1，并广播失效事件
+func (t *TieredCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := t.l2.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+	if err := t.l1.Set(ctx, key, value, t.l1Expiration(expiration)); err != nil {
+		return err
+	}
+	return t.publishInvalidation(ctx, key)
+}
+
+// Get 获取缓存，L1未命中时从L2读取，设置了L1TTL时回填L1
+func (t *TieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	err := t.l1.Get(ctx, key, dest)
+	if err == nil || !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+
+	var raw json.RawMessage
+	if err := t.l2.Get(ctx, key, &raw); err != nil {
+		return err
+	}
+	// 回填L1时无法得知L2剩余的过期时间，只有设置了L1TTL才回填，避免L1中的数据比L2存活更久
+	if t.l1TTL > 0 {
+		t.l1.setRaw(key, raw, t.l1TTL)
+	}
+	return decodeJSON(raw, dest, t.l1.useNumber)
+}
+
+// Delete 删除缓存，同时删除两级缓存并广播失效事件
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	if err := t.l2.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.l1.Delete(ctx, key)
+	return t.publishInvalidation(ctx, key)
+}
+
+// Exists 检查键是否存在
+func (t *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	exists, err := t.l1.Exists(ctx, key)
+	if err != nil || exists {
+		return exists, err
+	}
+	return t.l2.Exists(ctx, key)
+}
+
+// Close 停止失效订阅并关闭两级缓存
+func (t *TieredCache) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+		t.wg.Wait()
+	}
+	t.l1.Close()
+	return t.l2.Close()
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试二级缓存读取回填
+func TestTieredCacheGetSet(t *testing.T) {
+	ctx := context.Background()
+	l2 := NewFakeCache()
+	cache := NewTieredCache(NewMemoryCache(), l2, time.Minute)
+	defer cache.Close()
+
+	assert.NoError(t, l2.Set(ctx, "key", "from_l2", time.Hour))
+
+	// L1未命中，从L2读取并回填
+	var result string
+	assert.NoError(t, cache.Get(ctx, "key", &result))
+	assert.Equal(t, "from_l2", result)
+	assert.Equal(t, 1, l2.Hits())
+
+	// 第二次从L1读取
+	assert.NoError(t, cache.Get(ctx, "key", &result))
+	assert.Equal(t, 1, l2.Hits())
+
+	assert.NoError(t, cache.Delete(ctx, "key"))
+	assert.ErrorIs(t, cache.Get(ctx, "key", &result), ErrKeyNotFound)
+}
+
+// 测试回填的L1数据按L1TTL过期，未设置L1TTL时不回填
+func TestTieredCacheBackfillTTL(t *testing.T) {
+	ctx := context.Background()
+	l2 := NewFakeCache()
+	assert.NoError(t, l2.Set(ctx, "key", "v1", time.Hour))
+
+	cache := NewTieredCache(NewMemoryCache(), l2, 50*time.Millisecond)
+	var result string
+	assert.NoError(t, cache.Get(ctx, "key", &result))
+	assert.NoError(t, l2.Set(ctx, "key", "v2", time.Hour))
+	assert.NoError(t, cache.Get(ctx, "key", &result))
+	assert.Equal(t, "v1", result)
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, cache.Get(ctx, "key", &result))
+	assert.Equal(t, "v2", result)
+
+	noBackfill := NewTieredCache(NewMemoryCache(), l2, 0)
+	hits := l2.Hits()
+	assert.NoError(t, noBackfill.Get(ctx, "key", &result))
+	assert.NoError(t, noBackfill.Get(ctx, "key", &result))
+	assert.Equal(t, hits+2, l2.Hits())
+	assert.Zero(t, noBackfill.l1.Size())
+}
+
+// 测试多实例之间的失效广播
+func TestTieredCacheInvalidation(t *testing.T) {
+	client := newTestRedisClient(t)
+	ctx := context.Background()
+	key := "test:tiered:invalidate"
+	channel := "test:tiered:channel"
+	client.Del(ctx, key)
+
+	// 两个实例共享同一个Redis，各自拥有独立的L1
+	a := NewTieredCache(NewMemoryCache(), NewRedisCacheWithClient(client), time.Minute)
+	b := NewTieredCache(NewMemoryCache(), NewRedisCacheWithClient(client), time.Minute)
+	defer a.l1.Close()
+	defer b.l1.Close()
+	assert.NoError(t, a.EnableInvalidation(ctx, NewPubSub(client), channel))
+	assert.NoError(t, b.EnableInvalidation(ctx, NewPubSub(client), channel))
+	defer a.cancel()
+	defer b.cancel()
+
+	// b读取后L1中缓存了v1
+	assert.NoError(t, a.Set(ctx, key, "v1", time.Minute))
+	var result string
+	assert.NoError(t, b.Get(ctx, key, &result))
+	assert.Equal(t, "v1", result)
+
+	// a更新后，b的L1被清除，读到新值
+	assert.NoError(t, a.Set(ctx, key, "v2", time.Minute))
+	assert.Eventually(t, func() bool {
+		var got string
+		return b.Get(ctx, key, &got) == nil && got == "v2"
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// a删除后，b也读不到
+	assert.NoError(t, a.Delete(ctx, key))
+	assert.Eventually(t, func() bool {
+		var got string
+		return b.Get(ctx, key, &got) == ErrKeyNotFound
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lwy110193/go_vendor/utils"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// LoadFromYAML 从YAML文件加载配置到结构体
+// path: YAML文件路径
+// out: 配置结构体指针，如 *log.Config、*request.Config
+// 文件中的键名与结构体字段的yaml标签对应，未出现的键保持原值不变
+func LoadFromYAML(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return LoadFromYAMLBytes(data, out)
+}
+
+// LoadFromYAMLBytes 从YAML内容加载配置到结构体
+func LoadFromYAMLBytes(data []byte, out interface{}) error {
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal yaml: %w", err)
+	}
+	return nil
+}
+
+// LoadFromEnv 从环境变量加载配置到结构体
+// prefix: 环境变量前缀，如 "LOG"
+// out: 配置结构体指针
+// 变量名规则：前缀 + "_" + 字段名，字段名优先取yaml标签，否则取字段名的下划线形式，统一转为大写
+// 例如 log.Config.StdoutEnable(yaml:"stdout_enable") 对应 LOG_STDOUT_ENABLE
+// 支持的类型：string、bool、整型、浮点型、time.Duration、[]string(逗号分隔)、
+// map[string]string(k1=v1,k2=v2)、实现了encoding.TextUnmarshaler的类型、嵌套结构体
+// 未设置的环境变量不会覆盖字段原值，因此可以先加载默认配置/YAML再用环境变量覆盖
+func LoadFromEnv(prefix string, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("out must be a non-nil pointer to struct")
+	}
+	return loadStructFromEnv(strings.ToUpper(prefix), v.Elem())
+}
+
+// loadStructFromEnv 递归加载结构体字段
+func loadStructFromEnv(prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := fieldKey(field)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		// 匿名嵌入结构体与父结构体共用前缀
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := loadStructFromEnv(prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envName := strings.ToUpper(name)
+		if prefix != "" {
+			envName = prefix + "_" + envName
+		}
+
+		// 嵌套结构体，以字段名作为下一级前缀
+		if fv.Kind() == reflect.Struct && !fv.Addr().Type().Implements(textUnmarshalerType) {
+			if err := loadStructFromEnv(envName, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+		if err := setValue(fv, raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", envName, err)
+		}
+	}
+	return nil
+}
+
+// fieldKey 获取字段对应的键名，yaml标签为"-"时跳过
+func fieldKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", true
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, false
+	}
+	return utils.CamelStrConv(field.Name), false
+}
+
+// setValue 将字符串转换为字段类型并赋值
+func setValue(fv reflect.Value, raw string) error {
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		items := splitList(raw)
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", fv.Type().Key())
+		}
+		m := reflect.MakeMap(fv.Type())
+		for _, item := range splitList(raw) {
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid map item %q, want key=value", item)
+			}
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			if err := setValue(elem, strings.TrimSpace(kv[1])); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])).Convert(fv.Type().Key()), elem)
+		}
+		fv.Set(m)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// splitList 按逗号拆分并去除空白，空字符串返回空列表
+func splitList(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
@@ -0,0 +1,109 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/config"
+	"github.com/lwy110193/go_vendor/log"
+	"github.com/lwy110193/go_vendor/request"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadLogConfigFromEnv 测试从环境变量加载日志配置
+func TestLoadLogConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_STDOUT_ENABLE", "false")
+	t.Setenv("LOG_FILE_OUT_ENABLE", "true")
+	t.Setenv("LOG_OUTPUT_DIR", "/var/log/app")
+	t.Setenv("LOG_FILENAME", "service.log")
+	t.Setenv("LOG_MAX_SIZE", "50")
+	t.Setenv("LOG_ENCODING", "console")
+
+	// 先加载默认配置，未设置的环境变量保持默认值
+	cfg := log.DefaultConfig()
+	err := config.LoadFromEnv("LOG", &cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, log.DEBUG, cfg.Level)
+	assert.False(t, cfg.StdoutEnable)
+	assert.True(t, cfg.FileOutEnable)
+	assert.Equal(t, "/var/log/app", cfg.OutputDir)
+	assert.Equal(t, "service.log", cfg.Filename)
+	assert.Equal(t, 50, cfg.MaxSize)
+	assert.Equal(t, "console", cfg.Encoding)
+	assert.Equal(t, 7, cfg.MaxAge)
+}
+
+// TestLoadFromEnvInvalidValue 测试非法环境变量值返回错误
+func TestLoadFromEnvInvalidValue(t *testing.T) {
+	t.Setenv("LOG_MAX_SIZE", "abc")
+
+	cfg := log.Config{}
+	err := config.LoadFromEnv("LOG", &cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "LOG_MAX_SIZE")
+
+	assert.Error(t, config.LoadFromEnv("LOG", cfg))
+}
+
+// TestLoadInvalidLevel 测试无法识别的日志级别返回错误，不会被当作INFO
+func TestLoadInvalidLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "dbug")
+	cfg := log.DefaultConfig()
+	err := config.LoadFromEnv("LOG", &cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("level: warnn\n"), 0644))
+	assert.Error(t, config.LoadFromYAML(path, &cfg))
+}
+
+// TestLoadRequestConfigFromEnv 测试从环境变量加载请求配置
+func TestLoadRequestConfigFromEnv(t *testing.T) {
+	t.Setenv("HTTP_TIMEOUT", "5s")
+	t.Setenv("HTTP_RETRY_COUNT", "3")
+	t.Setenv("HTTP_PROXY_URLS", "http://p1:8080, http://p2:8080")
+	t.Setenv("HTTP_HEADERS", "X-App=demo,X-Env=test")
+
+	cfg := request.Config{}
+	err := config.LoadFromEnv("HTTP", &cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, 3, cfg.RetryCount)
+	assert.Equal(t, []string{"http://p1:8080", "http://p2:8080"}, cfg.ProxyURLs)
+	assert.Equal(t, map[string]string{"X-App": "demo", "X-Env": "test"}, cfg.Headers)
+}
+
+// TestLoadLogConfigFromYAML 测试从YAML文件加载日志配置
+func TestLoadLogConfigFromYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	content := `
+level: warn
+stdout_enable: true
+output_dir: ./logs
+filename: app.log
+by_date: true
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cfg := log.DefaultConfig()
+	err := config.LoadFromYAML(path, &cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, log.WARNING, cfg.Level)
+	assert.True(t, cfg.StdoutEnable)
+	assert.Equal(t, "./logs", cfg.OutputDir)
+	assert.Equal(t, "app.log", cfg.Filename)
+	assert.True(t, cfg.ByDate)
+	assert.Equal(t, 100, cfg.MaxSize)
+
+	// 环境变量覆盖YAML配置
+	t.Setenv("LOG_LEVEL", "error")
+	assert.NoError(t, config.LoadFromEnv("LOG", &cfg))
+	assert.Equal(t, log.ERROR, cfg.Level)
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/lwy110193/go_vendor/crontab"
 	mylog "github.com/lwy110193/go_vendor/log"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 type TaskLogger struct{}
@@ -73,3 +80,199 @@ func TestAddTask(t *testing.T) {
 
 	time.Sleep(100 * time.Second)
 }
+
+// flakyTask 前failures次执行返回错误，之后成功
+type flakyTask struct {
+	TestTask
+	failures int32
+	runs     atomic.Int32
+	done     chan struct{}
+}
+
+func (t *flakyTask) Run(ctx context.Context) error {
+	n := t.runs.Add(1)
+	if n <= t.failures {
+		return fmt.Errorf("attempt %d failed", n)
+	}
+	close(t.done)
+	return nil
+}
+
+func TestRunRetry(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(prev)
+
+	task := &flakyTask{TestTask: TestTask{Name: "flaky_task", Desc: "flaky_task_desc"}, failures: 2, done: make(chan struct{})}
+	crontab.Register(task)
+	crontab.Run([]*crontab.TaskConfig{{
+		Name:        "flaky_task",
+		Enabled:     true,
+		Immediately: true,
+		Spec:        "0 0 0 1 1 *",
+		MaxRetries:  3,
+		RetryDelay:  10 * time.Millisecond,
+	}})
+
+	select {
+	case <-task.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("task did not succeed, ran %d times", task.runs.Load())
+	}
+	if n := task.runs.Load(); n != 3 {
+		t.Errorf("task ran %d times, want 3", n)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	var retries int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "crontab_task_retries_total" {
+				for _, dp := range sum.DataPoints {
+					retries += dp.Value
+				}
+			}
+		}
+	}
+	if retries != 2 {
+		t.Errorf("crontab_task_retries_total = %d, want 2", retries)
+	}
+}
+
+// tickTask 记录每次执行所在的秒
+type tickTask struct {
+	TestTask
+	mu    sync.Mutex
+	ticks []int64
+}
+
+func (t *tickTask) Run(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ticks = append(t.ticks, time.Now().Unix())
+	return nil
+}
+
+func TestRunSingleton(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "192.168.3.42:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: "redis_MK8zA6"})
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("redis %s not available: %v", addr, err)
+	}
+
+	task := &tickTask{TestTask: TestTask{Name: "singleton_task", Desc: "singleton_task_desc"}}
+	crontab.Register(task)
+	conf := []*crontab.TaskConfig{{Name: "singleton_task", Enabled: true, Spec: "* * * * * *", Singleton: true}}
+
+	// 两个调度器模拟两个实例
+	a := crontab.Run(conf, crontab.WithSingletonClient(client))
+	b := crontab.Run(conf, crontab.WithSingletonClient(client))
+	time.Sleep(3500 * time.Millisecond)
+	<-a.Stop().Done()
+	<-b.Stop().Done()
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+	if len(task.ticks) < 2 {
+		t.Fatalf("task ran %d times, want at least 2", len(task.ticks))
+	}
+	seen := make(map[int64]bool)
+	for _, tick := range task.ticks {
+		if seen[tick] {
+			t.Errorf("task ran more than once at tick %d: %v", tick, task.ticks)
+		}
+		seen[tick] = true
+	}
+}
+
+// countTask 记录执行次数
+type countTask struct {
+	TestTask
+	runs atomic.Int32
+}
+
+func (t *countTask) Run(ctx context.Context) error {
+	t.runs.Add(1)
+	return nil
+}
+
+// TestRunSingletonInvalid 测试缺少WithSingletonClient或使用@every的Singleton任务被跳过，不会退出进程
+func TestRunSingletonInvalid(t *testing.T) {
+	noClient := &countTask{TestTask: TestTask{Name: "singleton_no_client", Desc: "singleton_no_client_desc"}}
+	every := &countTask{TestTask: TestTask{Name: "singleton_every", Desc: "singleton_every_desc"}}
+	crontab.Register(noClient)
+	crontab.Register(every)
+
+	// 只校验配置，不会连接Redis
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer client.Close()
+	for _, tc := range []struct {
+		conf []*crontab.TaskConfig
+		opts []crontab.RunOption
+	}{
+		{[]*crontab.TaskConfig{{Name: "singleton_no_client", Enabled: true, Immediately: true, Spec: "* * * * * *", Singleton: true}}, nil},
+		{[]*crontab.TaskConfig{{Name: "singleton_every", Enabled: true, Immediately: true, Spec: "@every 1s", Singleton: true}}, []crontab.RunOption{crontab.WithSingletonClient(client)}},
+	} {
+		c := crontab.Run(tc.conf, tc.opts...)
+		if n := len(c.Entries()); n != 0 {
+			t.Errorf("%s: %d entries scheduled, want 0", tc.conf[0].Name, n)
+		}
+		<-c.Stop().Done()
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := noClient.runs.Load() + every.runs.Load(); n != 0 {
+		t.Errorf("skipped tasks ran %d times, want 0", n)
+	}
+}
+
+// TestValidateSpec 测试任务表达式校验
+func TestValidateSpec(t *testing.T) {
+	for _, spec := range []string{"*/5 * * * * *", "0 30 2 * * *", "@every 1m", "@daily"} {
+		if err := crontab.ValidateSpec(spec); err != nil {
+			t.Errorf("ValidateSpec(%q) failed: %v", spec, err)
+		}
+	}
+	// 5个字段的标准表达式缺少秒字段，Run无法注册
+	for _, spec := range []string{"", "* * * * *", "61 * * * * *", "0 0 * * * mon-xyz", "@weird"} {
+		if err := crontab.ValidateSpec(spec); err == nil {
+			t.Errorf("ValidateSpec(%q) expected error", spec)
+		}
+	}
+}
+
+// TestNextRuns 测试预览后n次执行时间
+func TestNextRuns(t *testing.T) {
+	start := time.Now()
+	runs, err := crontab.NextRuns("0 0 * * * *", 3)
+	if err != nil {
+		t.Fatalf("NextRuns failed: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("Expected 3 runs, got %d", len(runs))
+	}
+	if !runs[0].After(start) || runs[0].Sub(start) > time.Hour {
+		t.Errorf("Unexpected first run %v (now %v)", runs[0], start)
+	}
+	for i, run := range runs {
+		if run.Minute() != 0 || run.Second() != 0 {
+			t.Errorf("Run %d not on the hour: %v", i, run)
+		}
+		if i > 0 && run.Sub(runs[i-1]) != time.Hour {
+			t.Errorf("Expected runs one hour apart, got %v and %v", runs[i-1], run)
+		}
+	}
+
+	if _, err := crontab.NextRuns("bad spec", 3); err == nil {
+		t.Error("Expected error for invalid spec")
+	}
+}
@@ -8,15 +8,22 @@ import (
 	"time"
 
 	mylog "github.com/lwy110193/go_vendor/log"
+	"github.com/lwy110193/go_vendor/utils"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // TaskConfig 任务配置
 type TaskConfig struct {
-	Name        string `yaml:"name"`        // 任务名称
-	Spec        string `yaml:"spec"`        // 任务表达式
-	Immediately bool   `yaml:"immediately"` // 是否启动时立即执行
-	Enabled     bool   `yaml:"enabled"`     // 启用状态
+	Name        string        `yaml:"name"`        // 任务名称
+	Spec        string        `yaml:"spec"`        // 任务表达式
+	Immediately bool          `yaml:"immediately"` // 是否启动时立即执行
+	Enabled     bool          `yaml:"enabled"`     // 启用状态
+	MaxRetries  int           `yaml:"max_retries"` // 单次执行失败后的最大重试次数，0表示不重试
+	RetryDelay  time.Duration `yaml:"retry_delay"` // 首次重试间隔，之后按指数退避，如 "1s"
+	Singleton   bool          `yaml:"singleton"`   // 多实例部署时每个调度时刻只在一个实例上执行，需通过WithSingletonClient提供Redis，不支持@every
 }
 
 type Logger struct {
@@ -61,11 +68,18 @@ func GetRegisteredList() []Task {
 	return list
 }
 
-// Run 初始化所有 task 并启动任务
-func Run(tasks []*TaskConfig) {
-	c := cron.New(cron.WithSeconds())
-
+// Run 初始化所有 task 并启动任务，返回已启动的调度器，可调用Stop停止
+// 任务执行失败时按TaskConfig.MaxRetries重试，每次重试记录日志并累加crontab_task_retries_total指标，
+// 重试耗尽后仍失败的记录错误日志，不会退出进程
+// Singleton任务未提供WithSingletonClient或使用@every表达式时记录日志并跳过该任务
+func Run(tasks []*TaskConfig, opts ...RunOption) *cron.Cron {
+	var options runOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	c := cron.New(cron.WithParser(specParser))
 	conf := getTaskConfig(tasks)
+	retries := newRetryCounter()
 	for _, taskItem := range list {
 		name := taskItem.GetName()
 		cfg, exist := conf[name]
@@ -75,18 +89,23 @@ func Run(tasks []*TaskConfig) {
 		if !cfg.Enabled {
 			continue
 		}
+		if cfg.Singleton {
+			if err := checkSingleton(cfg.Spec, options.lockClient); err != nil {
+				taskItem.Log().WriteLog(context.Background(), fmt.Sprintf("[Add Task: %s, skipped, err: %v]", taskItem.GetDesc(), err))
+				continue
+			}
+		}
 		if cfg.Immediately {
 			taskItem.Log().WriteLog(context.Background(), fmt.Sprintf("%sexecute immediately", time.Now().Format("2006-01-02 15:04:05")))
-			go func(t Task) {
-				if err := t.Run(context.Background()); err != nil {
-					t.Log().FatalLog(context.Background(), fmt.Sprintf("[Task: %s, err: %v]", t.GetDesc(), err))
-				}
-			}(taskItem)
+			go runTask(taskItem, cfg, retries)
 		}
-		_, err := c.AddFunc(cfg.Spec, func() {
-			if err := taskItem.Run(context.Background()); err != nil {
-				taskItem.Log().FatalLog(context.Background(), fmt.Sprintf("[Task: %s, err: %v]", taskItem.GetDesc(), err))
+		var id cron.EntryID
+		var err error
+		id, err = c.AddFunc(cfg.Spec, func() {
+			if cfg.Singleton && !claimTick(taskItem, options.lockClient, c.Entry(id).Prev) {
+				return
 			}
+			runTask(taskItem, cfg, retries)
 		})
 		if err != nil {
 			taskItem.Log().FatalLog(context.Background(), fmt.Sprintf("[Add Task: %s, conf: %+v, err: %v]", taskItem.GetDesc(), cfg, err))
@@ -94,6 +113,38 @@ func Run(tasks []*TaskConfig) {
 		taskItem.Log().WriteLog(context.Background(), fmt.Sprintf("[Add Task: %s, conf: %+v]", taskItem.GetDesc(), cfg))
 	}
 	c.Start()
+	return c
+}
+
+// newRetryCounter 通过全局MeterProvider创建任务重试次数指标，创建失败时不记录指标
+func newRetryCounter() metric.Int64Counter {
+	counter, err := otel.Meter("github.com/lwy110193/go_vendor/crontab").Int64Counter(
+		"crontab_task_retries_total",
+		metric.WithDescription("Number of cron task retries"),
+	)
+	if err != nil {
+		return nil
+	}
+	return counter
+}
+
+// runTask 执行一次任务，失败时按配置以指数退避重试
+func runTask(t Task, cfg *TaskConfig, retries metric.Int64Counter) {
+	ctx := context.Background()
+	attempt := 0
+	err := utils.Retry(ctx, cfg.MaxRetries+1, cfg.RetryDelay, nil, func() error {
+		if attempt > 0 {
+			t.Log().WriteLog(ctx, fmt.Sprintf("[Task: %s, retry %d/%d]", t.GetDesc(), attempt, cfg.MaxRetries))
+			if retries != nil {
+				retries.Add(ctx, 1, metric.WithAttributes(attribute.String("task", t.GetName())))
+			}
+		}
+		attempt++
+		return t.Run(ctx)
+	})
+	if err != nil {
+		t.Log().WriteLog(ctx, fmt.Sprintf("[Task: %s, failed after %d attempts, err: %v]", t.GetDesc(), attempt, err))
+	}
 }
 
 // getTaskConfig 从任务配置列表中构建任务配置映射
@@ -0,0 +1,65 @@
+package crontab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lwy110193/go_vendor/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+)
+
+// singletonLockTTL 调度时刻锁的过期时间，需大于各实例之间的时钟偏差
+// 锁的键包含调度时刻，执行完成后不释放，避免时钟稍慢的实例在锁释放后重复执行
+const singletonLockTTL = time.Minute
+
+// RunOption Run的可选配置
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	lockClient redis.UniversalClient
+}
+
+// WithSingletonClient 设置TaskConfig.Singleton任务使用的Redis，多个实例需使用同一个Redis
+func WithSingletonClient(client redis.UniversalClient) RunOption {
+	return func(o *runOptions) {
+		o.lockClient = client
+	}
+}
+
+// checkSingleton 校验Singleton任务能否按调度时刻加锁，不能时返回原因
+// @every按各实例的启动时间推算调度时刻，不同实例的锁键不一致，无法保证只执行一次
+// 表达式本身的错误由注册任务时报告，这里不处理
+func checkSingleton(spec string, client redis.UniversalClient) error {
+	if client == nil {
+		return errors.New("singleton requires WithSingletonClient")
+	}
+	schedule, err := specParser.Parse(spec)
+	if err != nil {
+		return nil
+	}
+	if _, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return fmt.Errorf("singleton does not support @every spec %q", spec)
+	}
+	return nil
+}
+
+// singletonKey 任务在某个调度时刻的锁键
+func singletonKey(name string, fireTime time.Time) string {
+	return fmt.Sprintf("crontab:singleton:%s:%d", name, fireTime.Unix())
+}
+
+// claimTick 抢占任务在fireTime这一调度时刻的执行权，只有一个实例能够成功
+// Redis出错时不执行，宁可漏跑一次也不重复执行
+// 启动时立即执行（Immediately）不经过该检查
+func claimTick(t Task, client redis.UniversalClient, fireTime time.Time) bool {
+	ctx := context.Background()
+	ok, err := cache.NewRedisLock(client, singletonKey(t.GetName(), fireTime), singletonLockTTL).TryLock(ctx)
+	if err != nil {
+		t.Log().WriteLog(ctx, fmt.Sprintf("[Task: %s, claim singleton lock failed, err: %v]", t.GetDesc(), err))
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,43 @@
+package crontab
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// specParser 任务表达式解析器，与Run使用的调度器一致：
+// 6个字段（秒 分 时 日 月 周），也支持@every 1m、@daily等描述符
+var specParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateSpec 校验任务表达式，可在加载配置时提前发现错误，避免Run注册任务失败
+func ValidateSpec(spec string) error {
+	if _, err := specParser.Parse(spec); err != nil {
+		return fmt.Errorf("invalid spec %q: %w", spec, err)
+	}
+	return nil
+}
+
+// NextRuns 计算任务表达式从当前时间起的后n次执行时间，用于预览调度计划
+func NextRuns(spec string, n int) ([]time.Time, error) {
+	if n <= 0 {
+		return nil, errors.New("n must be greater than 0")
+	}
+	schedule, err := specParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec %q: %w", spec, err)
+	}
+	runs := make([]time.Time, 0, n)
+	next := time.Now()
+	for i := 0; i < n; i++ {
+		next = schedule.Next(next)
+		// 永远不会触发的表达式（如2月30日）返回零值
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
@@ -0,0 +1,58 @@
+// Package ctxkey 提供类型化的context键及读写函数，避免使用字符串作为context键导致冲突
+package ctxkey
+
+import "context"
+
+// key context键类型，未导出，其他包无法构造相同的键
+type key int
+
+const (
+	traceIDKey key = iota
+	requestIDKey
+	userIDKey
+)
+
+// WithTraceID 返回携带traceID的context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// TraceID 获取context中的traceID
+func TraceID(ctx context.Context) (string, bool) {
+	return stringValue(ctx, traceIDKey)
+}
+
+// WithRequestID 返回携带请求ID的context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID 获取context中的请求ID
+func RequestID(ctx context.Context) (string, bool) {
+	return stringValue(ctx, requestIDKey)
+}
+
+// WithUserID 返回携带用户ID的context
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID 获取context中的用户ID
+func UserID(ctx context.Context) (string, bool) {
+	return stringValue(ctx, userIDKey)
+}
+
+// stringValue 读取字符串类型的值，不存在、类型不符或为空字符串时返回false
+func stringValue(ctx context.Context, k key) (string, bool) {
+	v, ok := ctx.Value(k).(string)
+	return v, ok && v != ""
+}
+
+// LegacyString 兼容以字符串为键存入context的旧代码，键不存在或值不是字符串时返回false
+func LegacyString(ctx context.Context, legacyKey string) (string, bool) {
+	if legacyKey == "" {
+		return "", false
+	}
+	v, ok := ctx.Value(legacyKey).(string)
+	return v, ok && v != ""
+}
@@ -0,0 +1,54 @@
+package ctxkey_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lwy110193/go_vendor/ctxkey"
+)
+
+type legacyKey string
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxkey.WithTraceID(ctx, "trace-1")
+	ctx = ctxkey.WithRequestID(ctx, "req-1")
+	ctx = ctxkey.WithUserID(ctx, "user-1")
+
+	tests := []struct {
+		name string
+		get  func(context.Context) (string, bool)
+		want string
+	}{
+		{"TraceID", ctxkey.TraceID, "trace-1"},
+		{"RequestID", ctxkey.RequestID, "req-1"},
+		{"UserID", ctxkey.UserID, "user-1"},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.get(ctx); !ok || got != tt.want {
+			t.Errorf("%s() = %q, %v, want %q", tt.name, got, ok, tt.want)
+		}
+		if got, ok := tt.get(context.Background()); ok || got != "" {
+			t.Errorf("%s() on empty context = %q, %v", tt.name, got, ok)
+		}
+	}
+}
+
+func TestNoCollisionWithStringKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "traceID", "string-key") //nolint:staticcheck // 测试与字符串键不冲突
+	ctx = context.WithValue(ctx, legacyKey("traceID"), "named-string-key")
+	if got, ok := ctxkey.TraceID(ctx); ok {
+		t.Errorf("TraceID() = %q, want absent", got)
+	}
+
+	ctx = ctxkey.WithTraceID(ctx, "typed")
+	if got, _ := ctxkey.TraceID(ctx); got != "typed" {
+		t.Errorf("TraceID() = %q, want typed", got)
+	}
+	if got, ok := ctxkey.LegacyString(ctx, "traceID"); !ok || got != "string-key" {
+		t.Errorf("LegacyString() = %q, %v, want string-key", got, ok)
+	}
+	if _, ok := ctxkey.LegacyString(ctx, "missing"); ok {
+		t.Error("LegacyString() for missing key should return false")
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/lwy110193/go_vendor/utils"
+	"gorm.io/gorm/clause"
+)
+
+// ToGormConditions 将utils.MI条件转换为gorm的条件表达式，可直接用于 db.Clauses(...)：
+//
+//	db.Model(model).Clauses(database.ToGormConditions(where)...).Find(&list)
+//
+// 运算符语义与ParseWhere相同，包括不带运算符的切片按NOT IN处理；与ParseWhere的差异：
+// 字段名与值由gorm按数据库方言转义，"表名.字段名"拆分为表和字段分别转义；LIKE的值作为参数传递；
+// 值为nil时生成IS NULL(ParseWhere不支持nil)。字段按名称排序后输出，保证生成的SQL稳定
+func ToGormConditions(where utils.MI) []clause.Expression {
+	fields := make([]string, 0, len(where))
+	for field := range where {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	exprs := make([]clause.Expression, 0, len(fields))
+	for _, field := range fields {
+		if expr := toGormCondition(toColumn(field), where[field]); expr != nil {
+			exprs = append(exprs, expr)
+		}
+	}
+	return exprs
+}
+
+// toColumn 转换字段名，支持 "表名.字段名" 格式
+func toColumn(field string) clause.Column {
+	if table, name, ok := strings.Cut(field, "."); ok {
+		return clause.Column{Table: table, Name: name}
+	}
+	return clause.Column{Name: field}
+}
+
+// toGormCondition 转换单个字段条件，空切片返回nil
+func toGormCondition(column clause.Column, value interface{}) clause.Expression {
+	if value == nil {
+		return clause.Eq{Column: column, Value: nil}
+	}
+	s := reflect.ValueOf(value)
+	if s.Kind() != reflect.Slice || s.Type().Elem().Kind() == reflect.Uint8 {
+		return clause.Eq{Column: column, Value: value}
+	}
+	if s.Len() == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, s.Len())
+	for i := range values {
+		values[i] = s.Index(i).Interface()
+	}
+	op := fmt.Sprintf("%v", values[0])
+
+	switch {
+	case len(values) == 2 && op == DCTypeLike:
+		return clause.Like{Column: column, Value: fmt.Sprintf("%%%v%%", values[1])}
+	case len(values) == 2 && op == DCTypeString:
+		return clause.Expr{SQL: fmt.Sprintf("%v", values[1])}
+	case len(values) == 2 && utils.InList(op, conditionList):
+		return compareCondition(op, column, values[1])
+	case len(values) == 3 && op == DCTypeBetween:
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, values[1], values[2]}}
+	case op == DCTypeIn:
+		if len(values) == 1 {
+			return nil
+		}
+		return clause.IN{Column: column, Values: values[1:]}
+	case op == DCTypeNotIn:
+		if len(values) == 1 {
+			return nil
+		}
+		return clause.Not(clause.IN{Column: column, Values: values[1:]})
+	default:
+		// 与ParseWhere保持一致，不带运算符的切片按NOT IN处理
+		return clause.Not(clause.IN{Column: column, Values: values})
+	}
+}
+
+// compareCondition 转换比较运算符条件
+func compareCondition(op string, column clause.Column, value interface{}) clause.Expression {
+	switch op {
+	case DCTypeGt:
+		return clause.Gt{Column: column, Value: value}
+	case DCTypeLt:
+		return clause.Lt{Column: column, Value: value}
+	case DCTypeGte:
+		return clause.Gte{Column: column, Value: value}
+	case DCTypeLte:
+		return clause.Lte{Column: column, Value: value}
+	case DCTypeNeq:
+		return clause.Neq{Column: column, Value: value}
+	default:
+		return clause.Eq{Column: column, Value: value}
+	}
+}
@@ -0,0 +1,64 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/lwy110193/go_vendor/database"
+	"github.com/lwy110193/go_vendor/utils"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// buildConditionSQL 使用DryRun模式生成查询SQL，不执行查询
+func buildConditionSQL(t *testing.T, dialector gorm.Dialector, where utils.MI) (string, []interface{}) {
+	t.Helper()
+	dryDb, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	var list []*TeTable
+	stmt := dryDb.Model(&TeTable{}).Clauses(database.ToGormConditions(where)...).Find(&list).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestToGormConditions(t *testing.T) {
+	dialectors := map[string]gorm.Dialector{
+		"mysql":  mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true}),
+		"sqlite": sqlite.Open(":memory:"),
+	}
+	tests := []struct {
+		name  string
+		where utils.MI
+		want  string
+		vars  int
+	}{
+		{"eq", utils.MI{"field1": "a"}, "SELECT * FROM `te_table` WHERE `field1` = ?", 1},
+		{"null", utils.MI{"field1": nil}, "SELECT * FROM `te_table` WHERE `field1` IS NULL", 0},
+		{"gt", utils.MI{"id": []interface{}{database.DCTypeGt, 1}}, "SELECT * FROM `te_table` WHERE `id` > ?", 1},
+		{"lt", utils.MI{"id": []interface{}{database.DCTypeLt, 1}}, "SELECT * FROM `te_table` WHERE `id` < ?", 1},
+		{"gte", utils.MI{"id": []interface{}{database.DCTypeGte, 1}}, "SELECT * FROM `te_table` WHERE `id` >= ?", 1},
+		{"lte", utils.MI{"id": []interface{}{database.DCTypeLte, 1}}, "SELECT * FROM `te_table` WHERE `id` <= ?", 1},
+		{"neq", utils.MI{"id": []interface{}{database.DCTypeNeq, 1}}, "SELECT * FROM `te_table` WHERE `id` <> ?", 1},
+		{"like", utils.MI{"field2": []string{database.DCTypeLike, "abc"}}, "SELECT * FROM `te_table` WHERE `field2` LIKE ?", 1},
+		{"between", utils.MI{"id": []interface{}{database.DCTypeBetween, 1, 10}}, "SELECT * FROM `te_table` WHERE `id` BETWEEN ? AND ?", 2},
+		{"in", utils.MI{"id": []interface{}{database.DCTypeIn, 1, 2, 3}}, "SELECT * FROM `te_table` WHERE `id` IN (?,?,?)", 3},
+		{"not_in", utils.MI{"id": []interface{}{database.DCTypeNotIn, 1, 2}}, "SELECT * FROM `te_table` WHERE `id` NOT IN (?,?)", 2},
+		{"slice", utils.MI{"id": []int{1, 2}}, "SELECT * FROM `te_table` WHERE `id` NOT IN (?,?)", 2},
+		{"string", utils.MI{"_": []string{database.DCTypeString, "field1 = field2"}}, "SELECT * FROM `te_table` WHERE field1 = field2", 0},
+		{"table", utils.MI{"te_table.field1": "a"}, "SELECT * FROM `te_table` WHERE `te_table`.`field1` = ?", 1},
+		{"multi", utils.MI{"field2": "b", "field1": "a"}, "SELECT * FROM `te_table` WHERE `field1` = ? AND `field2` = ?", 2},
+	}
+
+	for dialect, dialector := range dialectors {
+		for _, tt := range tests {
+			sql, vars := buildConditionSQL(t, dialector, tt.where)
+			if sql != tt.want {
+				t.Errorf("[%s] %s: sql = %q, want %q", dialect, tt.name, sql, tt.want)
+			}
+			if len(vars) != tt.vars {
+				t.Errorf("[%s] %s: vars = %v, want %d vars", dialect, tt.name, vars, tt.vars)
+			}
+		}
+	}
+}
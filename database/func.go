@@ -147,3 +147,30 @@ type PageInfo struct {
 	Page     int `json:"page"`
 	PageSize int `json:"page_size"`
 }
+
+// PageResult 分页查询结果
+type PageResult[T any] struct {
+	Items      []T   `json:"items"`       // 当前页数据
+	Total      int64 `json:"total"`       // 总记录数
+	Page       int   `json:"page"`        // 当前页码，从1开始
+	PageSize   int   `json:"page_size"`   // 每页数量
+	TotalPages int   `json:"total_pages"` // 总页数
+}
+
+// NewPageResult 创建分页结果，根据总数计算总页数
+func NewPageResult[T any](items []T, total int64, page, pageSize int) *PageResult[T] {
+	if items == nil {
+		items = []T{}
+	}
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+	}
+	return &PageResult[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MigrationStep 版本化的迁移步骤，按注册顺序执行，执行成功后记录到schema_migrations表，不会重复执行
+// SQL与Up至少设置一个，都设置时先执行SQL再执行Up；同一步骤在一个事务中执行
+// 注意MySQL的DDL语句会隐式提交，包含DDL的步骤失败时已执行的DDL无法回滚，建议每个步骤只包含一条DDL
+type MigrationStep struct {
+	// Version 唯一版本号，如"20240101_add_user_index"
+	Version string
+	// SQL 依次执行的SQL语句
+	SQL []string
+	// Up 自定义迁移函数，用于SQL无法表达的数据迁移
+	Up func(tx *gorm.DB) error
+}
+
+// schemaMigration 已执行的迁移记录
+type schemaMigration struct {
+	Version   string    `gorm:"primaryKey;column:version;size:191"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (m *schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 迁移执行器，先对注册的模型执行AutoMigrate，再按顺序执行未执行过的迁移步骤
+// 多个实例同时启动时不保证互斥，建议由单个实例或发布流程执行迁移
+type Migrator struct {
+	mu     sync.Mutex
+	models []schema.Tabler
+	steps  []MigrationStep
+}
+
+// NewMigrator 创建迁移执行器
+func NewMigrator() *Migrator {
+	return &Migrator{}
+}
+
+// RegisterModel 注册需要AutoMigrate的模型
+func (m *Migrator) RegisterModel(models ...schema.Tabler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.models = append(m.models, models...)
+}
+
+// RegisterMigration 注册迁移步骤，按注册顺序执行
+func (m *Migrator) RegisterMigration(steps ...MigrationStep) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, steps...)
+}
+
+// Migrate 对注册的模型及models执行AutoMigrate，再执行未执行过的迁移步骤
+// 重复执行是安全的，已执行的步骤会跳过
+func (m *Migrator) Migrate(db *gorm.DB, models ...schema.Tabler) error {
+	m.mu.Lock()
+	allModels := append(append([]schema.Tabler{}, m.models...), models...)
+	steps := append([]MigrationStep{}, m.steps...)
+	m.mu.Unlock()
+
+	if err := checkSteps(steps); err != nil {
+		return err
+	}
+
+	if len(allModels) > 0 {
+		dst := make([]interface{}, len(allModels))
+		for i, model := range allModels {
+			dst[i] = model
+		}
+		if err := db.AutoMigrate(dst...); err != nil {
+			return errors.Wrap(err, "auto migrate")
+		}
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return errors.Wrap(err, "create schema_migrations")
+	}
+	var applied []string
+	if err := db.Model(&schemaMigration{}).Pluck("version", &applied).Error; err != nil {
+		return errors.Wrap(err, "load applied migrations")
+	}
+	appliedSet := make(map[string]bool, len(applied))
+	for _, version := range applied {
+		appliedSet[version] = true
+	}
+
+	for _, step := range steps {
+		if appliedSet[step.Version] {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return runStep(tx, step)
+		}); err != nil {
+			return errors.Wrapf(err, "migration %s", step.Version)
+		}
+	}
+	return nil
+}
+
+// checkSteps 校验迁移步骤的版本号非空且不重复
+func checkSteps(steps []MigrationStep) error {
+	seen := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if step.Version == "" {
+			return errors.Errorf("migration %d: version is required", i)
+		}
+		if seen[step.Version] {
+			return errors.Errorf("migration %s: duplicate version", step.Version)
+		}
+		if len(step.SQL) == 0 && step.Up == nil {
+			return errors.Errorf("migration %s: SQL or Up is required", step.Version)
+		}
+		seen[step.Version] = true
+	}
+	return nil
+}
+
+// runStep 执行迁移步骤并记录版本
+func runStep(tx *gorm.DB, step MigrationStep) error {
+	for _, sql := range step.SQL {
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	if step.Up != nil {
+		if err := step.Up(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Create(&schemaMigration{Version: step.Version, AppliedAt: time.Now()}).Error
+}
+
+// defaultMigrator 包级别的迁移执行器
+var defaultMigrator = NewMigrator()
+
+// RegisterModel 向默认迁移执行器注册模型，通常在模型所在包的init中调用
+func RegisterModel(models ...schema.Tabler) {
+	defaultMigrator.RegisterModel(models...)
+}
+
+// RegisterMigration 向默认迁移执行器注册迁移步骤
+func RegisterMigration(steps ...MigrationStep) {
+	defaultMigrator.RegisterMigration(steps...)
+}
+
+// Migrate 使用默认迁移执行器执行迁移，models为注册模型之外需要AutoMigrate的模型
+func Migrate(db *gorm.DB, models ...schema.Tabler) error {
+	return defaultMigrator.Migrate(db, models...)
+}
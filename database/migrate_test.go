@@ -0,0 +1,100 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/lwy110193/go_vendor/database"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// migrateUser 迁移测试表
+type migrateUser struct {
+	ID   uint64 `gorm:"primaryKey;column:id"`
+	Name string `gorm:"column:name"`
+}
+
+func (m *migrateUser) TableName() string {
+	return "migrate_user"
+}
+
+func TestMigrator_Idempotent(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	upCalls := 0
+	migrator := database.NewMigrator()
+	migrator.RegisterModel(&migrateUser{})
+	migrator.RegisterMigration(
+		database.MigrationStep{
+			Version: "001_add_name_index",
+			SQL:     []string{"CREATE INDEX idx_migrate_user_name ON migrate_user(name)"},
+		},
+		database.MigrationStep{
+			Version: "002_seed_admin",
+			Up: func(tx *gorm.DB) error {
+				upCalls++
+				return tx.Create(&migrateUser{Name: "admin"}).Error
+			},
+		},
+	)
+
+	for i := 0; i < 2; i++ {
+		if err := migrator.Migrate(db); err != nil {
+			t.Fatalf("Migrate() run %d error = %v", i+1, err)
+		}
+	}
+
+	// 第二次执行不会重复执行迁移步骤
+	if upCalls != 1 {
+		t.Errorf("Up called %d times, want 1", upCalls)
+	}
+	var users int64
+	db.Model(&migrateUser{}).Count(&users)
+	if users != 1 {
+		t.Errorf("migrate_user has %d rows, want 1", users)
+	}
+	var versions []string
+	db.Table("schema_migrations").Order("version").Pluck("version", &versions)
+	if len(versions) != 2 || versions[0] != "001_add_name_index" || versions[1] != "002_seed_admin" {
+		t.Errorf("schema_migrations = %v", versions)
+	}
+	if !db.Migrator().HasIndex(&migrateUser{}, "idx_migrate_user_name") {
+		t.Error("index idx_migrate_user_name not created")
+	}
+}
+
+func TestMigrator_FailedStep(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	migrator := database.NewMigrator()
+	migrator.RegisterMigration(database.MigrationStep{
+		Version: "001_broken",
+		SQL:     []string{"CREATE TABLE broken_a (id INTEGER)", "NOT VALID SQL"},
+	})
+	if err := migrator.Migrate(db); err == nil {
+		t.Fatal("Migrate() error = nil, want error")
+	}
+	// 失败的步骤整体回滚且不记录版本
+	if db.Migrator().HasTable("broken_a") {
+		t.Error("broken_a should be rolled back")
+	}
+	var count int64
+	db.Table("schema_migrations").Count(&count)
+	if count != 0 {
+		t.Errorf("schema_migrations has %d rows, want 0", count)
+	}
+
+	// 重复的版本号
+	dup := database.NewMigrator()
+	step := database.MigrationStep{Version: "001", SQL: []string{"SELECT 1"}}
+	dup.RegisterMigration(step, step)
+	if err := dup.Migrate(db); err == nil {
+		t.Error("Migrate() with duplicate versions error = nil, want error")
+	}
+}
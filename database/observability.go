@@ -0,0 +1,94 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lwy110193/go_vendor/log"
+	"github.com/lwy110193/go_vendor/tracer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	// observabilitySpanKey 在语句实例中保存span的键
+	observabilitySpanKey = "go_vendor:observability_span"
+	// observabilityStartKey 在语句实例中保存开始时间的键
+	observabilityStartKey = "go_vendor:observability_start"
+)
+
+// UseObservability 为db的Create/Query/Update/Delete回调链注册trace span和结构化日志
+// 每次操作生成一个名为gorm.<op>的span，包含表名、SQL（带占位符，不含参数）和影响行数；
+// span包住整个回调链，模型的BeforeCreate等钩子在span内执行，可通过tx.Statement.Context获取该span
+// logger: 为nil时只生成span，否则通过LogResult记录每次操作的耗时和结果
+// tracerName: otel.Tracer的名称
+// gorm.ErrRecordNotFound不视为错误
+func UseObservability(db *gorm.DB, logger *log.Logger, tracerName string) error {
+	tracer := otel.Tracer(tracerName)
+	callback := db.Callback()
+	type register func(name string, fn func(*gorm.DB)) error
+	steps := []struct {
+		op            string
+		before, after register
+	}{
+		{"create", callback.Create().Before("*").Register, callback.Create().After("*").Register},
+		{"query", callback.Query().Before("*").Register, callback.Query().After("*").Register},
+		{"update", callback.Update().Before("*").Register, callback.Update().After("*").Register},
+		{"delete", callback.Delete().Before("*").Register, callback.Delete().After("*").Register},
+	}
+	for _, step := range steps {
+		if err := step.before("observability:before_"+step.op, startObservation(tracer, step.op)); err != nil {
+			return err
+		}
+		if err := step.after("observability:after_"+step.op, endObservation(logger, step.op)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startObservation 开始span并记录开始时间，span所在的ctx写回Statement.Context
+func startObservation(tracer trace.Tracer, op string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := tracer.Start(tx.Statement.Context, "gorm."+op, trace.WithSpanKind(trace.SpanKindClient))
+		tx.Statement.Context = ctx
+		tx.InstanceSet(observabilitySpanKey, span)
+		tx.InstanceSet(observabilityStartKey, time.Now())
+	}
+}
+
+// endObservation 结束span并记录日志
+func endObservation(logger *log.Logger, op string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(observabilitySpanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		err := tx.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+
+		table, sql := tx.Statement.Table, tx.Statement.SQL.String()
+		tracer.SetDBAttributes(span, tx.Dialector.Name(), sql)
+		span.SetAttributes(
+			attribute.String("db.table", table),
+			attribute.Int64("db.rows_affected", tx.RowsAffected),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		if logger != nil {
+			start, _ := tx.InstanceGet(observabilityStartKey)
+			startTime, _ := start.(time.Time)
+			logger.LogResult(tx.Statement.Context, "gorm."+op, startTime, err, "table", table, "sql", sql, "rows", tx.RowsAffected)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/lwy110193/go_vendor/database"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// observedUser 测试表，BeforeCreate钩子记录执行时是否处于span中
+type observedUser struct {
+	ID   uint64 `gorm:"primaryKey;column:id"`
+	Name string `gorm:"column:name"`
+
+	hookInSpan bool `gorm:"-"`
+}
+
+func (u *observedUser) TableName() string {
+	return "observed_user"
+}
+
+func (u *observedUser) BeforeCreate(tx *gorm.DB) error {
+	u.hookInSpan = trace.SpanFromContext(tx.Statement.Context).SpanContext().IsValid()
+	return nil
+}
+
+func TestUseObservability(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.AutoMigrate(&observedUser{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	if err := database.UseObservability(db, nil, "database_test"); err != nil {
+		t.Fatalf("UseObservability() error = %v", err)
+	}
+
+	user := &observedUser{Name: "alice"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !user.hookInSpan {
+		t.Error("BeforeCreate hook should run inside the span")
+	}
+	var found observedUser
+	if err := db.First(&found, "id = ?", 999).Error; err == nil {
+		t.Fatal("First() expected ErrRecordNotFound")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	create := spans[0]
+	if create.Name() != "gorm.create" {
+		t.Errorf("span name = %q, want gorm.create", create.Name())
+	}
+	attrs := map[string]string{}
+	for _, kv := range create.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["db.table"] != "observed_user" || attrs["db.rows_affected"] != "1" || attrs["db.statement"] == "" || attrs["db.system"] != "sqlite" {
+		t.Errorf("unexpected create span attributes: %v", attrs)
+	}
+	if spans[1].Name() != "gorm.query" || spans[1].Status().Code != codes.Unset {
+		t.Errorf("query span = %q status %v, record not found should not be an error", spans[1].Name(), spans[1].Status())
+	}
+}
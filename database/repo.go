@@ -3,6 +3,8 @@ package database
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/lwy110193/go_vendor/utils"
 	"github.com/pkg/errors"
@@ -13,18 +15,39 @@ import (
 type BaseRepo struct {
 	Db    *gorm.DB
 	Model schema.Tabler
+	// QueryTimeout 默认的单次操作超时时间，0表示不限制
+	// 仅在调用方传入的ctx没有设置deadline时生效，调用方的deadline优先
+	QueryTimeout time.Duration
+	// TenantColumn 租户字段名，如"tenant_id"，为空表示不开启租户隔离
+	// 开启后Find/FindOne/FindOrCreate/Update/Updates/Delete等按条件操作的方法会自动追加
+	// "TenantColumn = 租户ID"条件，租户ID通过WithTenant放入context，缺少时返回ErrTenantRequired；
+	// Create及Raw/Exec等原生SQL不做处理，需调用方自行保证
+	TenantColumn string
 }
 
-// Find 查找数据
+// withTimeout 调用方ctx未设置deadline时，按QueryTimeout包装超时
+func (r *BaseRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.QueryTimeout)
+}
+
+// Find 查找数据，where按ToGormConditions转换，字段名和值由gorm按数据库方言转义
 func (r *BaseRepo) Find(ctx context.Context, resultList interface{}, where utils.MI, info *DbExtInfo, fieldList ...string) (cnt int64, err error) {
-	db := r.Db.WithContext(ctx).Model(r.Model)
-	query, args := ParseWhere(where)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err = r.scopeTenant(ctx, where)
+	if err != nil {
+		return 0, err
+	}
+	db := r.Db.WithContext(ctx).Model(r.Model).Clauses(ToGormConditions(where)...)
 	if len(fieldList) > 0 {
 		db = db.Select(fieldList)
 	}
-	if len(query) > 0 {
-		db = db.Where(query, args...)
-	}
 
 	if info != nil {
 		if info.PageInfo != nil {
@@ -43,6 +66,12 @@ func (r *BaseRepo) Find(ctx context.Context, resultList interface{}, where utils
 
 // FindOne 查找一条数据
 func (r *BaseRepo) FindOne(ctx context.Context, result interface{}, where utils.MI, fieldList ...string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err := r.scopeTenant(ctx, where)
+	if err != nil {
+		return err
+	}
 	db := r.Db.WithContext(ctx).Model(r.Model)
 	query, args := ParseWhere(where)
 	if len(fieldList) > 0 {
@@ -58,8 +87,78 @@ func (r *BaseRepo) FindOne(ctx context.Context, result interface{}, where utils.
 	return nil
 }
 
+// FindOrCreate 查找一条数据，不存在时创建
+// result: 查询结果，记录不存在时会填充为新建的数据
+// where: 查询条件，格式同ParseWhere
+// defaults: 记录不存在时插入的数据，应包含where中的条件字段；开启租户隔离时租户字段为零值会自动填充为context中的租户，与context不一致时返回错误
+// 并发去重依赖数据库的唯一索引：需在查询字段上建立唯一索引，并发插入时后到的一方收到唯一键冲突，
+// 此时重新查询并返回已存在的记录(created为false)；没有唯一索引时并发调用仍可能插入重复数据。
+// 查询与插入不在同一事务中执行，否则REPEATABLE READ下冲突后的重新查询读不到对方已提交的记录
+func (r *BaseRepo) FindOrCreate(ctx context.Context, result schema.Tabler, where utils.MI, defaults schema.Tabler) (created bool, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err = r.scopeTenant(ctx, where)
+	if err != nil {
+		return false, err
+	}
+
+	db := r.Db.WithContext(ctx)
+	query, args := ParseWhere(where)
+	findOne := func() error {
+		tx := db.Model(r.Model)
+		if len(query) > 0 {
+			tx = tx.Where(query, args...)
+		}
+		return tx.First(result).Error
+	}
+
+	findErr := findOne()
+	if findErr == nil {
+		return false, nil
+	}
+	if !errors.Is(findErr, gorm.ErrRecordNotFound) {
+		return false, errors.WithStack(findErr)
+	}
+
+	if err := r.applyTenant(ctx, defaults); err != nil {
+		return false, err
+	}
+	if err := db.Create(defaults).Error; err != nil {
+		if !isDuplicateKey(db, err) {
+			return false, errors.WithStack(err)
+		}
+		// 并发调用方已插入相同的记录，返回该记录
+		if findErr := findOne(); findErr != nil {
+			return false, errors.WithStack(findErr)
+		}
+		return false, nil
+	}
+
+	// 类型一致时直接复制新建的数据，否则按条件重新查询
+	resultValue, defaultsValue := reflect.ValueOf(result), reflect.ValueOf(defaults)
+	if resultValue.Type() == defaultsValue.Type() && resultValue.Kind() == reflect.Ptr {
+		resultValue.Elem().Set(defaultsValue.Elem())
+		return true, nil
+	}
+	if err := findOne(); err != nil {
+		return true, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+// isDuplicateKey 判断错误是否为唯一键冲突，未开启gorm.Config.TranslateError时通过方言的错误转换判断
+func isDuplicateKey(db *gorm.DB, err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	return ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+}
+
 // Create 创建一条数据
 func (r *BaseRepo) Create(ctx context.Context, data schema.Tabler) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	if err := r.Db.WithContext(ctx).Create(data).Error; err != nil {
 		return errors.WithStack(err)
 	}
@@ -68,6 +167,8 @@ func (r *BaseRepo) Create(ctx context.Context, data schema.Tabler) error {
 
 // CreateBatch 创建多条数据
 func (r *BaseRepo) CreateBatch(ctx context.Context, list interface{}, batchSize int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	if err := r.Db.WithContext(ctx).CreateInBatches(list, batchSize).Error; err != nil {
 		return errors.WithStack(err)
 	}
@@ -76,19 +177,38 @@ func (r *BaseRepo) CreateBatch(ctx context.Context, list interface{}, batchSize
 
 // Update 更新数据 - 通过map更新数据
 func (r *BaseRepo) Update(ctx context.Context, where, upt utils.MI) error {
+	_, err := r.UpdateN(ctx, where, upt)
+	return err
+}
+
+// UpdateN 更新数据 - 通过map更新数据，返回受影响的行数
+func (r *BaseRepo) UpdateN(ctx context.Context, where, upt utils.MI) (rowsAffected int64, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err = r.scopeTenant(ctx, where)
+	if err != nil {
+		return 0, err
+	}
 	db := r.Db.WithContext(ctx).Model(r.Model)
 	query, args := ParseWhere(where)
 	if len(query) > 0 {
 		db = db.Where(query, args...)
 	}
-	if err := db.Where(where).Updates(upt).Error; err != nil {
-		return errors.WithStack(err)
+	db = db.Updates(map[string]interface{}(upt))
+	if db.Error != nil {
+		return 0, errors.WithStack(db.Error)
 	}
-	return nil
+	return db.RowsAffected, nil
 }
 
 // Updates 更新数据 - 通过对象更新数据 - 更新对象中的非零值字段
 func (r *BaseRepo) Updates(ctx context.Context, data schema.Tabler, where utils.MI) (err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err = r.scopeTenant(ctx, where)
+	if err != nil {
+		return err
+	}
 	if r.Model != data {
 		return errors.New("model not equal")
 	}
@@ -99,6 +219,12 @@ func (r *BaseRepo) Updates(ctx context.Context, data schema.Tabler, where utils.
 
 // UpdatesWithZeroValue 更新数据 - 通过对象更新数据 - 更新对象中全部字段
 func (r *BaseRepo) UpdatesWithZeroValue(ctx context.Context, data schema.Tabler, where utils.MI, ignoreFields ...string) (err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err = r.scopeTenant(ctx, where)
+	if err != nil {
+		return err
+	}
 	if r.Model != data {
 		return errors.New("model not equal")
 	}
@@ -113,19 +239,51 @@ func (r *BaseRepo) UpdatesWithZeroValue(ctx context.Context, data schema.Tabler,
 			delete(mapData, field)
 		}
 	}
-	err = r.Db.WithContext(ctx).Model(data).Where(whereStr, params...).Updates(mapData).Error
+	err = r.Db.WithContext(ctx).Model(data).Where(whereStr, params...).Updates(map[string]interface{}(mapData)).Error
 	return errors.WithStack(err)
 }
 
 // Delete 删除数据
 func (r *BaseRepo) Delete(ctx context.Context, where utils.MI) error {
+	_, err := r.DeleteN(ctx, where)
+	return err
+}
+
+// DeleteN 删除数据，返回受影响的行数
+func (r *BaseRepo) DeleteN(ctx context.Context, where utils.MI) (rowsAffected int64, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	where, err = r.scopeTenant(ctx, where)
+	if err != nil {
+		return 0, err
+	}
 	db := r.Db.WithContext(ctx)
 	query, args := ParseWhere(where)
 	if len(query) > 0 {
 		db = db.Where(query, args...)
 	}
-	if err := db.Delete(r.Model).Error; err != nil {
-		return errors.WithStack(err)
+	db = db.Delete(r.Model)
+	if db.Error != nil {
+		return 0, errors.WithStack(db.Error)
 	}
-	return nil
+	return db.RowsAffected, nil
+}
+
+// FindPage 分页查询，返回带分页信息的结果
+// page: 页码，从1开始，小于1时按1处理
+// pageSize: 每页数量，小于1时按10处理
+func FindPage[T any](ctx context.Context, r *BaseRepo, where utils.MI, page, pageSize int, fieldList ...string) (*PageResult[T], error) {
+	page = utils.Max(page, 1)
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	var items []T
+	total, err := r.Find(ctx, &items, where, &DbExtInfo{
+		PageInfo: &PageInfo{Page: page, PageSize: pageSize},
+	}, fieldList...)
+	if err != nil {
+		return nil, err
+	}
+	return NewPageResult(items, total, page, pageSize), nil
 }
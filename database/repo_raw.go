@@ -16,6 +16,8 @@ import (
 
 // Raw 原始SQL查询
 func (r *BaseRepo) Raw(ctx context.Context, result interface{}, sql string, params ...interface{}) (err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	err = r.Db.WithContext(ctx).Raw(sql, params...).Scan(result).Error
 	if err != nil {
 		return err
@@ -25,15 +27,25 @@ func (r *BaseRepo) Raw(ctx context.Context, result interface{}, sql string, para
 
 // Exec 执行原始SQL语句
 func (r *BaseRepo) Exec(ctx context.Context, sql string, params ...interface{}) (err error) {
-	err = r.Db.WithContext(ctx).Exec(sql, params...).Error
-	if err != nil {
-		return err
+	_, err = r.ExecN(ctx, sql, params...)
+	return err
+}
+
+// ExecN 执行原始SQL语句，返回受影响的行数
+func (r *BaseRepo) ExecN(ctx context.Context, sql string, params ...interface{}) (rowsAffected int64, err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	db := r.Db.WithContext(ctx).Exec(sql, params...)
+	if db.Error != nil {
+		return 0, db.Error
 	}
-	return nil
+	return db.RowsAffected, nil
 }
 
 // Transaction 事务处理
 func (r *BaseRepo) Transaction(ctx context.Context, fun func(tx *gorm.DB) error) (err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	err = r.Db.WithContext(ctx).Transaction(fun)
 	if err != nil {
 		return perrors.WithStack(err)
@@ -43,6 +55,8 @@ func (r *BaseRepo) Transaction(ctx context.Context, fun func(tx *gorm.DB) error)
 
 // UpdateOrInsert 更新或插入；更新数量为0时插入，需指定更新条件字段
 func (r *BaseRepo) UpdateOrInsert(ctx context.Context, data schema.Tabler, updateWhereField []string, ignoreUpdateField []string) (err error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 	if len(updateWhereField) == 0 {
 		return
 	}
@@ -2,7 +2,9 @@ package database_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,37 +13,63 @@ import (
 	"github.com/lwy110193/go_vendor/log"
 	"github.com/lwy110193/go_vendor/utils"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-var db *gorm.DB
+var (
+	mysqlOnce sync.Once
+	mysqlDb   *gorm.DB
+	mysqlErr  error
+)
+
+// mysqlDB 返回测试用的MySQL连接，连接不可用时跳过当前测试
+// 只在第一次调用时建立连接，不可用的结果同样会被缓存，避免每个测试都等待连接超时
+func mysqlDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	mysqlOnce.Do(func() {
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local&timeout=3s&readTimeout=3s",
+			"root",
+			"mysql_8j5rrb",
+			"192.168.3.42",
+			3306,
+			"stock",
+		)
+		mysqlDb, mysqlErr = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger: nil,
+		})
+		if mysqlErr != nil {
+			return
+		}
 
-func init() {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"mysql_8j5rrb",
-		"192.168.3.42",
-		3306,
-		"stock",
-	)
+		// 获取底层的sql.DB对象进行连接池配置
+		sqlDB, err := mysqlDb.DB()
+		if err != nil {
+			mysqlErr = err
+			return
+		}
 
-	var err error
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: nil,
+		// 设置连接池
+		sqlDB.SetMaxIdleConns(10)
+		sqlDB.SetMaxOpenConns(100)
 	})
-	if err != nil {
-		panic(fmt.Sprintf("Failed to connect to MySQL database: %v", err))
+	if mysqlErr != nil {
+		t.Skipf("MySQL unavailable: %v", mysqlErr)
 	}
+	return mysqlDb
+}
 
-	// 获取底层的sql.DB对象进行连接池配置
-	sqlDB, err := db.DB()
+// newTeTableDB 使用内存SQLite创建te_table表，用于不依赖MySQL特性的测试
+func newTeTableDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	sqliteDb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
-		panic(fmt.Sprintf("Failed to get underlying sql.DB: %v", err))
+		t.Fatalf("gorm.Open() error = %v", err)
 	}
-
-	// 设置连接池
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	if err := sqliteDb.AutoMigrate(&TeTable{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	return sqliteDb
 }
 
 // StockInfo 表示股票信息表
@@ -57,6 +85,7 @@ func (s *StockInfo) TableName() string {
 }
 
 func TestBaseRepo_Find(t *testing.T) {
+	db := mysqlDB(t)
 	repo := database.BaseRepo{
 		Db:    db,
 		Model: &StockInfo{},
@@ -76,7 +105,43 @@ func TestBaseRepo_Find(t *testing.T) {
 	}
 }
 
+// TestBaseRepo_FindConditions 测试Find按ToGormConditions的语义过滤并统计总数
+func TestBaseRepo_FindConditions(t *testing.T) {
+	repo := database.BaseRepo{
+		Db:    newTeTableDB(t),
+		Model: &TeTable{},
+	}
+	for _, field1 := range []string{"a", "b", "c", "d"} {
+		if err := repo.Create(context.Background(), &TeTable{Field1: field1, Field2: "x" + field1}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		where utils.MI
+		want  int64
+	}{
+		{"in", utils.MI{"field1": []string{database.DCTypeIn, "a", "b"}}, 2},
+		{"slice", utils.MI{"field1": []string{"a", "b", "c"}}, 1},
+		{"like", utils.MI{"field2": []string{database.DCTypeLike, "c"}}, 1},
+		{"table", utils.MI{"te_table.field1": "d"}, 1},
+	}
+	for _, tt := range tests {
+		var list []*TeTable
+		info := &database.DbExtInfo{PageInfo: &database.PageInfo{Page: 1, PageSize: 1}}
+		cnt, err := repo.Find(context.Background(), &list, tt.where, info)
+		if err != nil {
+			t.Fatalf("%s: Find() error = %v", tt.name, err)
+		}
+		if cnt != tt.want || len(list) != 1 {
+			t.Errorf("%s: Find() cnt = %d, rows = %d, want cnt %d and 1 row", tt.name, cnt, len(list), tt.want)
+		}
+	}
+}
+
 func TestBaseRepo_FindOne(t *testing.T) {
+	db := mysqlDB(t)
 	repo := database.BaseRepo{
 		Db:    db,
 		Model: &StockInfo{},
@@ -104,7 +169,7 @@ func (t *TeTable) TableName() string {
 }
 
 func Test_CreateTableTe(t *testing.T) {
-	err := db.AutoMigrate(&TeTable{})
+	err := mysqlDB(t).AutoMigrate(&TeTable{})
 	if err != nil {
 		t.Errorf("AutoMigrate() error = %v", err)
 		return
@@ -133,7 +198,7 @@ type Stock struct {
 }
 
 func TestCreateInBatch(t *testing.T) {
-	repo := NewStockInfoRepo(db, nil)
+	repo := NewStockInfoRepo(mysqlDB(t), nil)
 
 	var resultList []*Stock
 	count, err := repo.Find(context.Background(), &resultList, utils.MI{}, nil, "stock_id", "display_name")
@@ -220,3 +285,173 @@ func TestCreateInBatch(t *testing.T) {
 		return
 	}
 }
+
+func TestBaseRepo_FindOrCreate(t *testing.T) {
+	repo := database.BaseRepo{
+		Db:    newTeTableDB(t),
+		Model: &TeTable{},
+	}
+
+	field1 := utils.RandNumCode(10)
+	where := utils.MI{"field1": field1}
+	defer repo.Delete(context.Background(), where)
+
+	// 第一次调用，记录不存在，创建
+	first := &TeTable{}
+	created, err := repo.FindOrCreate(context.Background(), first, where, &TeTable{Field1: field1, Field2: "first"})
+	if err != nil {
+		t.Errorf("FindOrCreate() error = %v", err)
+		return
+	}
+	if !created || first.ID == 0 {
+		t.Errorf("FindOrCreate() created = %v, id = %v, want created", created, first.ID)
+		return
+	}
+
+	// 第二次调用，查到已存在的记录
+	second := &TeTable{}
+	created, err = repo.FindOrCreate(context.Background(), second, where, &TeTable{Field1: field1, Field2: "second"})
+	if err != nil {
+		t.Errorf("FindOrCreate() error = %v", err)
+		return
+	}
+	if created {
+		t.Errorf("FindOrCreate() created = true, want false")
+	}
+	if second.ID != first.ID || second.Field2 != "first" {
+		t.Errorf("FindOrCreate() got id = %v field2 = %v, want id = %v field2 = first", second.ID, second.Field2, first.ID)
+	}
+}
+
+// uniqueItem 带唯一索引的测试表
+type uniqueItem struct {
+	ID   uint64 `gorm:"primaryKey;column:id"`
+	Code string `gorm:"column:code;uniqueIndex"`
+	Name string `gorm:"column:name"`
+}
+
+func (u *uniqueItem) TableName() string {
+	return "unique_item"
+}
+
+func TestBaseRepo_FindOrCreateConflict(t *testing.T) {
+	sqliteDb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := sqliteDb.AutoMigrate(&uniqueItem{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	// 内存数据库每个连接相互独立，限制为单个连接
+	sqlDB, _ := sqliteDb.DB()
+	sqlDB.SetMaxOpenConns(1)
+	// 模拟并发：查询未命中之后、插入之前，另一个调用方先插入了相同的记录
+	raced := false
+	sqliteDb.Callback().Query().After("gorm:query").Register("test:race", func(tx *gorm.DB) {
+		if !raced {
+			raced = true
+			sqliteDb.Exec("INSERT INTO unique_item (code, name) VALUES (?, ?)", "c1", "winner")
+		}
+	})
+	repo := database.BaseRepo{Db: sqliteDb, Model: &uniqueItem{}}
+
+	result := &uniqueItem{}
+	created, err := repo.FindOrCreate(context.Background(), result, utils.MI{"code": "c1"}, &uniqueItem{Code: "c1", Name: "loser"})
+	if err != nil {
+		t.Fatalf("FindOrCreate() error = %v", err)
+	}
+	if created || result.Name != "winner" {
+		t.Errorf("FindOrCreate() created = %v, name = %v, want existing row from the winner", created, result.Name)
+	}
+	var count int64
+	sqliteDb.Model(&uniqueItem{}).Count(&count)
+	if count != 1 {
+		t.Errorf("rows = %d, want 1", count)
+	}
+}
+
+func TestBaseRepo_QueryTimeout(t *testing.T) {
+	repo := database.BaseRepo{
+		Db:           mysqlDB(t),
+		Model:        &TeTable{},
+		QueryTimeout: 100 * time.Millisecond,
+	}
+
+	// 调用方未设置deadline，使用默认超时
+	var result []int
+	start := time.Now()
+	err := repo.Raw(context.Background(), &result, "SELECT SLEEP(2)")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Raw() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Raw() elapsed = %v, want about 100ms", elapsed)
+	}
+
+	// 调用方设置的deadline优先
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = repo.Raw(ctx, &result, "SELECT SLEEP(0.3)"); err != nil {
+		t.Errorf("Raw() with caller deadline error = %v", err)
+	}
+}
+
+func TestBaseRepo_RowsAffected(t *testing.T) {
+	repo := database.BaseRepo{
+		Db:    newTeTableDB(t),
+		Model: &TeTable{},
+	}
+
+	// 没有匹配的记录，受影响行数为0且不返回错误
+	where := utils.MI{"field1": "not_exists_" + utils.RandNumCode(10)}
+	n, err := repo.UpdateN(context.Background(), where, utils.MI{"field2": "updated"})
+	if err != nil || n != 0 {
+		t.Errorf("UpdateN() = %v, %v, want 0, nil", n, err)
+	}
+	n, err = repo.DeleteN(context.Background(), where)
+	if err != nil || n != 0 {
+		t.Errorf("DeleteN() = %v, %v, want 0, nil", n, err)
+	}
+	n, err = repo.ExecN(context.Background(), "update te_table set field2 = ? where field1 = ?", "updated", where["field1"])
+	if err != nil || n != 0 {
+		t.Errorf("ExecN() = %v, %v, want 0, nil", n, err)
+	}
+}
+
+func TestNewPageResult(t *testing.T) {
+	tests := []struct {
+		total      int64
+		pageSize   int
+		totalPages int
+	}{
+		{total: 0, pageSize: 10, totalPages: 0},
+		{total: 20, pageSize: 10, totalPages: 2},
+		{total: 21, pageSize: 10, totalPages: 3},
+		{total: 7, pageSize: 3, totalPages: 3},
+		{total: 1, pageSize: 10, totalPages: 1},
+	}
+	for _, tt := range tests {
+		result := database.NewPageResult([]*TeTable{}, tt.total, 1, tt.pageSize)
+		if result.TotalPages != tt.totalPages {
+			t.Errorf("NewPageResult(total=%v, pageSize=%v) TotalPages = %v, want %v", tt.total, tt.pageSize, result.TotalPages, tt.totalPages)
+		}
+	}
+}
+
+func TestFindPage(t *testing.T) {
+	repo := &database.BaseRepo{
+		Db:    newTeTableDB(t),
+		Model: &TeTable{},
+	}
+	result, err := database.FindPage[*TeTable](context.Background(), repo, utils.MI{}, 1, 3)
+	if err != nil {
+		t.Errorf("FindPage() error = %v", err)
+		return
+	}
+	if len(result.Items) > 3 {
+		t.Errorf("FindPage() items = %v, want <= 3", len(result.Items))
+	}
+	if want := int((result.Total + 2) / 3); result.TotalPages != want {
+		t.Errorf("FindPage() TotalPages = %v, want %v", result.TotalPages, want)
+	}
+}
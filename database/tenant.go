@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/lwy110193/go_vendor/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ErrTenantRequired BaseRepo开启租户隔离但context中没有租户时返回的错误
+var ErrTenantRequired = errors.New("tenant required in context")
+
+// tenantCtxKey context中租户ID的键
+type tenantCtxKey struct{}
+
+// WithTenant 返回携带租户ID的context，供开启租户隔离的BaseRepo使用
+func WithTenant(ctx context.Context, tenantID interface{}) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+// TenantFromContext 获取context中的租户ID
+func TenantFromContext(ctx context.Context) (tenantID interface{}, ok bool) {
+	tenantID = ctx.Value(tenantCtxKey{})
+	return tenantID, tenantID != nil
+}
+
+// scopeTenant 开启租户隔离时，在查询条件中追加租户条件
+// 返回新的条件，不修改调用方传入的where；context中缺少租户或where中的租户与context不一致时返回错误
+func (r *BaseRepo) scopeTenant(ctx context.Context, where utils.MI) (utils.MI, error) {
+	if r.TenantColumn == "" {
+		return where, nil
+	}
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, errors.WithStack(ErrTenantRequired)
+	}
+	if value, exists := where[r.TenantColumn]; exists && !sameTenant(value, tenantID) {
+		return nil, errors.Errorf("tenant mismatch: where %s=%v, context tenant=%v", r.TenantColumn, value, tenantID)
+	}
+
+	scoped := make(utils.MI, len(where)+1)
+	for key, value := range where {
+		scoped[key] = value
+	}
+	scoped[r.TenantColumn] = tenantID
+	return scoped, nil
+}
+
+// sameTenant 判断两个租户ID是否相同，按字符串形式比较，使int与int64等不同整数类型的同一租户视为一致
+func sameTenant(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// applyTenant 开启租户隔离时，将context中的租户写入待插入数据的租户字段
+// 字段为零值时自动填充；已有值且与context不一致，或数据中没有该字段时返回错误
+func (r *BaseRepo) applyTenant(ctx context.Context, value interface{}) error {
+	if r.TenantColumn == "" {
+		return nil
+	}
+	tenantID, ok := TenantFromContext(ctx)
+	if !ok {
+		return errors.WithStack(ErrTenantRequired)
+	}
+	stmt := &gorm.Statement{DB: r.Db}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField(r.TenantColumn)
+	if field == nil {
+		return errors.Errorf("tenant column %s not found in %s", r.TenantColumn, stmt.Schema.Name)
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	current, zero := field.ValueOf(ctx, rv)
+	if zero {
+		return field.Set(ctx, rv, tenantID)
+	}
+	if !sameTenant(current, tenantID) {
+		return errors.Errorf("tenant mismatch: %s=%v, context tenant=%v", r.TenantColumn, current, tenantID)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lwy110193/go_vendor/database"
+	"github.com/lwy110193/go_vendor/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// tenantItem 租户隔离测试表
+type tenantItem struct {
+	ID       uint64 `gorm:"primaryKey;column:id"`
+	TenantID int64  `gorm:"column:tenant_id"`
+	Name     string `gorm:"column:name"`
+}
+
+func (t *tenantItem) TableName() string {
+	return "tenant_item"
+}
+
+// newTenantRepo 使用内存SQLite创建开启租户隔离的BaseRepo，并写入两个租户的数据
+func newTenantRepo(t *testing.T) *database.BaseRepo {
+	t.Helper()
+	sqliteDb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := sqliteDb.AutoMigrate(&tenantItem{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	items := []*tenantItem{
+		{TenantID: 1, Name: "a"},
+		{TenantID: 1, Name: "b"},
+		{TenantID: 2, Name: "a"},
+	}
+	if err := sqliteDb.Create(items).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return &database.BaseRepo{Db: sqliteDb, Model: &tenantItem{}, TenantColumn: "tenant_id"}
+}
+
+func TestBaseRepo_TenantScope(t *testing.T) {
+	repo := newTenantRepo(t)
+	ctx := database.WithTenant(context.Background(), int64(1))
+
+	// 查询自动追加租户条件
+	var list []*tenantItem
+	if _, err := repo.Find(ctx, &list, utils.MI{}, nil); err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Errorf("Find() got %d rows, want 2", len(list))
+	}
+	for _, item := range list {
+		if item.TenantID != 1 {
+			t.Errorf("Find() returned row of tenant %d", item.TenantID)
+		}
+	}
+
+	var one tenantItem
+	if err := repo.FindOne(database.WithTenant(context.Background(), int64(2)), &one, utils.MI{"name": "a"}); err != nil {
+		t.Fatalf("FindOne() error = %v", err)
+	}
+	if one.TenantID != 2 {
+		t.Errorf("FindOne() returned row of tenant %d, want 2", one.TenantID)
+	}
+
+	// 更新、删除只影响当前租户
+	n, err := repo.UpdateN(ctx, utils.MI{"name": "a"}, utils.MI{"name": "c"})
+	if err != nil || n != 1 {
+		t.Errorf("UpdateN() = %d, %v, want 1 row", n, err)
+	}
+	n, err = repo.DeleteN(ctx, utils.MI{})
+	if err != nil || n != 2 {
+		t.Errorf("DeleteN() = %d, %v, want 2 rows", n, err)
+	}
+	var count int64
+	repo.Db.Model(&tenantItem{}).Where("tenant_id = ? AND name = ?", 2, "a").Count(&count)
+	if count != 1 {
+		t.Errorf("tenant 2 row was modified, count = %d", count)
+	}
+}
+
+func TestBaseRepo_TenantRequired(t *testing.T) {
+	repo := newTenantRepo(t)
+
+	var list []*tenantItem
+	if _, err := repo.Find(context.Background(), &list, utils.MI{}, nil); !errors.Is(err, database.ErrTenantRequired) {
+		t.Errorf("Find() without tenant error = %v, want ErrTenantRequired", err)
+	}
+	if err := repo.Delete(context.Background(), utils.MI{}); !errors.Is(err, database.ErrTenantRequired) {
+		t.Errorf("Delete() without tenant error = %v, want ErrTenantRequired", err)
+	}
+
+	// where中指定其他租户时报错
+	ctx := database.WithTenant(context.Background(), int64(1))
+	if _, err := repo.Find(ctx, &list, utils.MI{"tenant_id": int64(2)}, nil); err == nil {
+		t.Error("Find() with mismatched tenant should fail")
+	}
+
+	var count int64
+	repo.Db.Model(&tenantItem{}).Count(&count)
+	if count != 3 {
+		t.Errorf("rows = %d, want 3 (nothing deleted)", count)
+	}
+}
+
+func TestBaseRepo_TenantFindOrCreate(t *testing.T) {
+	repo := newTenantRepo(t)
+	ctx := database.WithTenant(context.Background(), int64(2))
+
+	// 未填写租户字段时自动使用context中的租户，再次调用能查到而不是重复插入
+	for i := 0; i < 2; i++ {
+		var result tenantItem
+		created, err := repo.FindOrCreate(ctx, &result, utils.MI{"name": "new"}, &tenantItem{Name: "new"})
+		if err != nil {
+			t.Fatalf("FindOrCreate() error = %v", err)
+		}
+		if created != (i == 0) || result.TenantID != 2 {
+			t.Errorf("FindOrCreate() #%d created = %v, tenant = %d", i, created, result.TenantID)
+		}
+	}
+	var count int64
+	repo.Db.Model(&tenantItem{}).Where("tenant_id = ? AND name = ?", 2, "new").Count(&count)
+	if count != 1 {
+		t.Errorf("rows of tenant 2 named new = %d, want 1", count)
+	}
+
+	// defaults中的租户与context不一致时报错
+	var result tenantItem
+	if _, err := repo.FindOrCreate(ctx, &result, utils.MI{"name": "other"}, &tenantItem{TenantID: 1, Name: "other"}); err == nil {
+		t.Error("FindOrCreate() with mismatched tenant in defaults should fail")
+	}
+
+	// where中租户的整数类型与context不同时视为同一租户
+	if _, err := repo.FindOrCreate(ctx, &result, utils.MI{"tenant_id": 2, "name": "new"}, &tenantItem{Name: "new"}); err != nil {
+		t.Errorf("FindOrCreate() with int tenant in where error = %v", err)
+	}
+}
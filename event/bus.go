@@ -0,0 +1,99 @@
+package event
+
+import (
+	"sync"
+)
+
+// Option 事件总线配置项
+type Option func(*options)
+
+type options struct {
+	async bool
+}
+
+// WithAsync 异步投递：Publish 为每个订阅者启动协程后立即返回，订阅者之间的执行顺序不保证
+func WithAsync() Option {
+	return func(o *options) {
+		o.async = true
+	}
+}
+
+// subscriber 订阅者
+type subscriber[T any] struct {
+	id uint64
+	fn func(T)
+}
+
+// Bus 进程内的类型化事件总线，并发安全
+// 同步模式下 Publish 按订阅顺序依次调用订阅者，全部返回后 Publish 才返回
+type Bus[T any] struct {
+	mu     sync.RWMutex
+	subs   []subscriber[T]
+	nextID uint64
+	async  bool
+	wg     sync.WaitGroup
+}
+
+// NewBus 创建事件总线
+func NewBus[T any](opts ...Option) *Bus[T] {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Bus[T]{async: o.async}
+}
+
+// Subscribe 订阅事件，返回取消订阅函数，取消订阅函数可重复调用
+func (b *Bus[T]) Subscribe(fn func(T)) (unsubscribe func()) {
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscriber[T]{id: id, fn: fn})
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			for i, sub := range b.subs {
+				if sub.id == id {
+					b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// Publish 发布事件给当前所有订阅者
+// 订阅者在锁外调用，可以在回调中订阅或取消订阅
+func (b *Bus[T]) Publish(evt T) {
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if !b.async {
+			sub.fn(evt)
+			continue
+		}
+		b.wg.Add(1)
+		go func(fn func(T)) {
+			defer b.wg.Done()
+			fn(evt)
+		}(sub.fn)
+	}
+}
+
+// Len 返回当前订阅者数量
+func (b *Bus[T]) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Wait 等待已发布的异步事件全部投递完成，同步模式下立即返回
+func (b *Bus[T]) Wait() {
+	b.wg.Wait()
+}
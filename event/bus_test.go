@@ -0,0 +1,99 @@
+package event_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lwy110193/go_vendor/event"
+)
+
+// TestBusMultipleSubscribers 测试多个订阅者按订阅顺序收到事件
+func TestBusMultipleSubscribers(t *testing.T) {
+	bus := event.NewBus[string]()
+
+	var got []string
+	bus.Subscribe(func(s string) { got = append(got, "a:"+s) })
+	bus.Subscribe(func(s string) { got = append(got, "b:"+s) })
+
+	bus.Publish("x")
+	bus.Publish("y")
+
+	want := []string{"a:x", "b:x", "a:y", "b:y"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// TestBusUnsubscribe 测试取消订阅后不再收到事件，且重复取消不影响其他订阅者
+func TestBusUnsubscribe(t *testing.T) {
+	bus := event.NewBus[int]()
+
+	var a, b int
+	unsubA := bus.Subscribe(func(n int) { a += n })
+	bus.Subscribe(func(n int) { b += n })
+
+	bus.Publish(1)
+	unsubA()
+	unsubA()
+	bus.Publish(2)
+
+	if a != 1 {
+		t.Errorf("a = %d, want 1", a)
+	}
+	if b != 3 {
+		t.Errorf("b = %d, want 3", b)
+	}
+	if bus.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", bus.Len())
+	}
+}
+
+// TestBusUnsubscribeInHandler 测试在回调中取消订阅不会死锁
+func TestBusUnsubscribeInHandler(t *testing.T) {
+	bus := event.NewBus[int]()
+
+	var calls int
+	var unsub func()
+	unsub = bus.Subscribe(func(int) {
+		calls++
+		unsub()
+	})
+
+	bus.Publish(1)
+	bus.Publish(2)
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+// TestBusAsync 测试异步投递和并发发布
+func TestBusAsync(t *testing.T) {
+	bus := event.NewBus[int](event.WithAsync())
+
+	var sum atomic.Int64
+	for i := 0; i < 3; i++ {
+		bus.Subscribe(func(n int) { sum.Add(int64(n)) })
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			bus.Publish(n)
+		}(i)
+	}
+	wg.Wait()
+	bus.Wait()
+
+	if got := sum.Load(); got != 3*5050 {
+		t.Errorf("sum = %d, want %d", got, 3*5050)
+	}
+}
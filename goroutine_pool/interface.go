@@ -1,9 +1,9 @@
 package goroutine_pool
 
 import (
-	"fmt"
 	"sync"
 
+	"github.com/lwy110193/go_vendor/utils"
 	"github.com/panjf2000/ants/v2"
 )
 
@@ -32,9 +32,9 @@ func (p *pool) Submit(task func()) error {
 		func() {
 			defer p.wg.Done()
 			defer func() {
-				if err := recover(); err != nil {
+				if err := utils.RecoverToError(recover()); err != nil {
 					p.mtx.Lock()
-					p.errList = append(p.errList, fmt.Errorf("panic: %v", err))
+					p.errList = append(p.errList, err)
 					p.mtx.Unlock()
 				}
 			}()
@@ -67,9 +67,9 @@ func NewFuncPool(size int, runTask func(i interface{}), opts ...ants.Option) (*f
 	p, err := ants.NewPoolWithFunc(size, func(i interface{}) {
 		defer func() {
 			defer pool.wg.Done()
-			if err := recover(); err != nil {
+			if err := utils.RecoverToError(recover()); err != nil {
 				pool.mtx.Lock()
-				pool.errList = append(pool.errList, fmt.Errorf("panic: %v", err))
+				pool.errList = append(pool.errList, err)
 				pool.mtx.Unlock()
 			}
 		}()
@@ -0,0 +1,106 @@
+package goroutine_pool
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lwy110193/go_vendor/utils"
+	"github.com/panjf2000/ants/v2"
+)
+
+// defaultRetryBackoff 任务重试的默认初始间隔，之后每次翻倍
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// NewWorkerQueue 新建带重试与死信处理的任务队列，任务在协程池中执行
+// 处理函数返回错误或panic时最多重试retries次，仍失败则调用onDead（可为nil）
+func NewWorkerQueue(size, retries int, onDead func(job any, err error), opts ...ants.Option) (*workerQueue, error) {
+	if size <= 0 {
+		size = 50
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	p, err := ants.NewPool(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &workerQueue{
+		pool:    p,
+		retries: retries,
+		backoff: defaultRetryBackoff,
+		onDead:  onDead,
+	}, nil
+}
+
+type workerQueue struct {
+	pool    *ants.Pool
+	retries int
+	backoff time.Duration
+	onDead  func(job any, err error)
+	wg      sync.WaitGroup
+}
+
+// SetBackoff 设置重试的初始间隔，之后每次翻倍，需在Enqueue之前调用
+func (q *workerQueue) SetBackoff(backoff time.Duration) {
+	q.backoff = backoff
+}
+
+// Enqueue 提交任务，任务最终成功或进入死信后才视为完成
+func (q *workerQueue) Enqueue(job any, handler func(any) error) error {
+	q.wg.Add(1)
+	if err := q.submit(job, handler, 0); err != nil {
+		q.wg.Done()
+		return err
+	}
+	return nil
+}
+
+// submit 提交第attempt次执行
+// 重试等待通过定时器重新提交，不占用协程池的工作协程
+func (q *workerQueue) submit(job any, handler func(any) error, attempt int) error {
+	return q.pool.Submit(func() {
+		err := runHandler(job, handler)
+		if err == nil {
+			q.wg.Done()
+			return
+		}
+		if attempt >= q.retries {
+			q.dead(job, err)
+			return
+		}
+		time.AfterFunc(q.backoff<<attempt, func() {
+			if submitErr := q.submit(job, handler, attempt+1); submitErr != nil {
+				q.dead(job, fmt.Errorf("resubmit failed: %w, last error: %v", submitErr, err))
+			}
+		})
+	})
+}
+
+// runHandler 执行处理函数，panic转换为错误
+func runHandler(job any, handler func(any) error) (err error) {
+	defer func() {
+		if panicErr := utils.RecoverToError(recover()); panicErr != nil {
+			err = panicErr
+		}
+	}()
+	return handler(job)
+}
+
+// dead 任务重试耗尽，交给死信处理
+func (q *workerQueue) dead(job any, err error) {
+	defer q.wg.Done()
+	if q.onDead != nil {
+		q.onDead(job, err)
+	}
+}
+
+// Wait 等待所有已提交的任务完成（成功或进入死信）
+func (q *workerQueue) Wait() {
+	q.wg.Wait()
+}
+
+// Release 释放协程池，应在Wait之后调用，否则等待重试的任务会直接进入死信
+func (q *workerQueue) Release() {
+	q.pool.Release()
+}
@@ -0,0 +1,81 @@
+package goroutine_pool_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goroutinepool "github.com/lwy110193/go_vendor/goroutine_pool"
+)
+
+func TestWorkerQueueDeadLetter(t *testing.T) {
+	errFailed := errors.New("always fails")
+
+	var mu sync.Mutex
+	var deadJobs []any
+	var deadErr error
+	queue, err := goroutinepool.NewWorkerQueue(2, 3, func(job any, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deadJobs = append(deadJobs, job)
+		deadErr = err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Release()
+	queue.SetBackoff(time.Millisecond)
+
+	var calls atomic.Int32
+	if err := queue.Enqueue("job-1", func(job any) error {
+		calls.Add(1)
+		return errFailed
+	}); err != nil {
+		t.Fatal(err)
+	}
+	queue.Wait()
+
+	// 首次执行 + 3次重试
+	if got := calls.Load(); got != 4 {
+		t.Errorf("handler calls = %d, want 4", got)
+	}
+	if len(deadJobs) != 1 || deadJobs[0] != "job-1" {
+		t.Errorf("dead jobs = %v, want [job-1]", deadJobs)
+	}
+	if !errors.Is(deadErr, errFailed) {
+		t.Errorf("dead error = %v, want %v", deadErr, errFailed)
+	}
+}
+
+func TestWorkerQueueRetrySucceeds(t *testing.T) {
+	var dead atomic.Int32
+	queue, err := goroutinepool.NewWorkerQueue(2, 3, func(any, error) { dead.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Release()
+	queue.SetBackoff(time.Millisecond)
+
+	var calls atomic.Int32
+	var attempts sync.Map
+	for i := 0; i < 5; i++ {
+		_ = queue.Enqueue(i, func(job any) error {
+			calls.Add(1)
+			// 每个任务第一次panic，之后成功
+			if _, retried := attempts.LoadOrStore(job, true); !retried {
+				panic("first attempt")
+			}
+			return nil
+		})
+	}
+	queue.Wait()
+
+	if dead.Load() != 0 {
+		t.Errorf("dead = %d, want 0", dead.Load())
+	}
+	if got := calls.Load(); got != 10 {
+		t.Errorf("handler calls = %d, want 10", got)
+	}
+}
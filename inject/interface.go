@@ -13,6 +13,14 @@ func GetContainer() *dig.Container {
 	return digContainer
 }
 
+// Reset 重新初始化全局容器，同时清空全局Lifecycle中已注册的关闭函数（不会执行它们）
+// 仅用于测试：在测试函数之间调用，避免构造函数跨测试泄漏以及重复Provide报错；
+// 不是并发安全的，不要在业务代码或并行测试中调用
+func Reset() {
+	digContainer = dig.New()
+	defaultLifecycle = NewLifecycle()
+}
+
 func Provide(constructor any, opts ...dig.ProvideOption) error {
 	return digContainer.Provide(constructor, opts...)
 }
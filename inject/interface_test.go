@@ -0,0 +1,39 @@
+package inject
+
+import (
+	"testing"
+)
+
+type resetTestUser struct {
+	Name string
+}
+
+func TestReset(t *testing.T) {
+	defer Reset()
+
+	constructor := func() *resetTestUser { return &resetTestUser{Name: "first"} }
+	if err := Provide(constructor); err != nil {
+		t.Fatalf("Provide() error = %v", err)
+	}
+	// 未重置时重复注册同一类型会报错
+	if err := Provide(constructor); err == nil {
+		t.Fatal("expected error when providing the same type twice")
+	}
+	RegisterShutdown("noop", nil)
+
+	Reset()
+
+	if err := Provide(func() *resetTestUser { return &resetTestUser{Name: "second"} }); err != nil {
+		t.Fatalf("Provide() after Reset error = %v", err)
+	}
+	var user *resetTestUser
+	if err := Resolve(&user); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if user.Name != "second" {
+		t.Errorf("user.Name = %q, want second", user.Name)
+	}
+	if n := len(GetLifecycle().closers); n != 0 {
+		t.Errorf("lifecycle closers = %d after Reset, want 0", n)
+	}
+}
@@ -0,0 +1,115 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout ctx未设置deadline时Shutdown使用的默认超时时间
+var DefaultShutdownTimeout = 30 * time.Second
+
+// Lifecycle 组件关闭注册表
+// 组件在初始化完成后注册关闭函数，程序退出时调用Shutdown按注册的逆序依次关闭，
+// 保证后初始化(通常依赖先初始化组件)的组件先关闭，例如先释放协程池再关闭日志
+type Lifecycle struct {
+	mu      sync.Mutex
+	closers []namedCloser
+}
+
+// namedCloser 带名称的关闭函数
+type namedCloser struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// NewLifecycle 创建关闭注册表
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Register 注册关闭函数
+func (l *Lifecycle) Register(name string, fn func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closers = append(l.closers, namedCloser{name: name, fn: fn})
+}
+
+// RegisterCloser 注册io.Closer，如cache.Cache、log.Logger
+func (l *Lifecycle) RegisterCloser(name string, closer io.Closer) {
+	l.Register(name, func(ctx context.Context) error {
+		return closer.Close()
+	})
+}
+
+// RegisterFunc 注册无返回值的关闭函数，如协程池的Release、InitTracer返回的关闭函数
+func (l *Lifecycle) RegisterFunc(name string, fn func()) {
+	l.Register(name, func(ctx context.Context) error {
+		fn()
+		return nil
+	})
+}
+
+// Shutdown 按注册的逆序关闭所有组件，返回合并后的错误
+// ctx未设置deadline时使用DefaultShutdownTimeout；超时后剩余组件不再关闭，并在错误中列出
+// 已执行的关闭函数会从注册表中移除，重复调用是安全的
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	closers := l.closers
+	l.closers = nil
+	l.mu.Unlock()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultShutdownTimeout)
+		defer cancel()
+	}
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		closer := closers[i]
+		done := make(chan error, 1)
+		go func() {
+			done <- closer.fn(ctx)
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", closer.name, err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("%s: %w", closer.name, ctx.Err()))
+			for j := i - 1; j >= 0; j-- {
+				errs = append(errs, fmt.Errorf("%s: not closed: %w", closers[j].name, ctx.Err()))
+			}
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var defaultLifecycle = NewLifecycle()
+
+// GetLifecycle 获取全局关闭注册表
+func GetLifecycle() *Lifecycle {
+	return defaultLifecycle
+}
+
+// RegisterShutdown 向全局注册表注册关闭函数
+func RegisterShutdown(name string, fn func(ctx context.Context) error) {
+	defaultLifecycle.Register(name, fn)
+}
+
+// RegisterCloser 向全局注册表注册io.Closer
+func RegisterCloser(name string, closer io.Closer) {
+	defaultLifecycle.RegisterCloser(name, closer)
+}
+
+// Shutdown 关闭全局注册表中的所有组件
+func Shutdown(ctx context.Context) error {
+	return defaultLifecycle.Shutdown(ctx)
+}
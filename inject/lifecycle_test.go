@@ -0,0 +1,75 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCloser struct {
+	name  string
+	order *[]string
+	err   error
+}
+
+func (c *testCloser) Close() error {
+	*c.order = append(*c.order, c.name)
+	return c.err
+}
+
+// TestLifecycleShutdownOrder 测试逆序关闭与错误合并
+func TestLifecycleShutdownOrder(t *testing.T) {
+	var order []string
+	errCache := errors.New("cache close failed")
+	errLogger := errors.New("logger sync failed")
+
+	lc := NewLifecycle()
+	lc.RegisterCloser("logger", &testCloser{name: "logger", order: &order, err: errLogger})
+	lc.RegisterFunc("tracer", func() { order = append(order, "tracer") })
+	lc.RegisterCloser("cache", &testCloser{name: "cache", order: &order, err: errCache})
+	lc.Register("pool", func(ctx context.Context) error {
+		order = append(order, "pool")
+		return nil
+	})
+
+	err := lc.Shutdown(context.Background())
+	want := []string{"pool", "cache", "tracer", "logger"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+	if !errors.Is(err, errCache) || !errors.Is(err, errLogger) {
+		t.Errorf("Expected joined errors, got %v", err)
+	}
+
+	// 重复调用不会再次关闭
+	order = nil
+	if err := lc.Shutdown(context.Background()); err != nil || len(order) != 0 {
+		t.Errorf("Expected no-op on second shutdown, got err=%v order=%v", err, order)
+	}
+}
+
+// TestLifecycleShutdownTimeout 测试关闭超时
+func TestLifecycleShutdownTimeout(t *testing.T) {
+	lc := NewLifecycle()
+	lc.RegisterFunc("first", func() {})
+	lc.Register("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := lc.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "first: not closed") {
+		t.Errorf("Expected remaining closer listed, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Shutdown did not respect timeout")
+	}
+}
@@ -0,0 +1,86 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeLimiter 用于测试的限流器，实现Limiter接口
+// 不依赖Redis与真实时间，行为完全确定：
+// 未设置上限时按allow统一放行或拒绝；设置上限后每个key累计通过limit个请求后拒绝
+type FakeLimiter struct {
+	mu sync.Mutex
+	// 未设置上限时是否放行
+	allow bool
+	// 每个key允许通过的请求总数，<=0表示不限制
+	limit int64
+	// 每个key已通过的请求数
+	used map[string]int64
+	// 调用次数
+	calls int
+}
+
+// NewFakeLimiter 创建测试用限流器
+// allow: 是否放行所有请求
+func NewFakeLimiter(allow bool) *FakeLimiter {
+	return &FakeLimiter{
+		allow: allow,
+		used:  make(map[string]int64),
+	}
+}
+
+// SetLimit 设置每个key允许通过的请求总数，超过后拒绝
+func (f *FakeLimiter) SetLimit(limit int64) *FakeLimiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limit = limit
+	return f
+}
+
+// Allow 判断是否允许通过
+func (f *FakeLimiter) Allow(ctx context.Context, key string) (bool, int64, error) {
+	return f.AllowN(ctx, key, 1)
+}
+
+// AllowN 判断是否允许通过N个请求
+// 返回值: 是否允许通过，剩余令牌数(未设置上限时为-1)
+func (f *FakeLimiter) AllowN(ctx context.Context, key string, n int64) (bool, int64, error) {
+	if ctx.Err() != nil {
+		return false, 0, ctx.Err()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	if f.limit <= 0 {
+		return f.allow, -1, nil
+	}
+
+	used := f.used[key]
+	if used+n > f.limit {
+		return false, f.limit - used, nil
+	}
+	f.used[key] = used + n
+	return true, f.limit - used - n, nil
+}
+
+// Calls 获取Allow/AllowN的调用次数
+func (f *FakeLimiter) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// Reset 清空已通过的请求计数
+func (f *FakeLimiter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.used = make(map[string]int64)
+	f.calls = 0
+}
+
+// Close 关闭限流器
+func (f *FakeLimiter) Close() error {
+	return nil
+}
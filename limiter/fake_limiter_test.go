@@ -0,0 +1,45 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFakeLimiterDenyAfterN 测试FakeLimiter在N次请求后拒绝
+func TestFakeLimiterDenyAfterN(t *testing.T) {
+	ctx := context.Background()
+	var limiter Limiter = NewFakeLimiter(true).SetLimit(3)
+
+	for i := 0; i < 3; i++ {
+		allowed, remaining, err := limiter.Allow(ctx, "api:user:1")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, int64(2-i), remaining)
+	}
+
+	allowed, remaining, err := limiter.Allow(ctx, "api:user:1")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int64(0), remaining)
+
+	// 不同key独立计数
+	allowed, _, err = limiter.Allow(ctx, "api:user:2")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 5, limiter.(*FakeLimiter).Calls())
+}
+
+// TestFakeLimiterFixed 测试未设置上限时统一放行或拒绝
+func TestFakeLimiterFixed(t *testing.T) {
+	ctx := context.Background()
+
+	allowed, _, err := NewFakeLimiter(true).AllowN(ctx, "key", 100)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, err = NewFakeLimiter(false).Allow(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+}
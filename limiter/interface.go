@@ -25,11 +25,11 @@ type Limiter interface {
 // Config 限流器配置
 type Config struct {
 	// Rate: 每秒生成的令牌数
-	Rate int64
+	Rate int64 `yaml:"rate"`
 	// Burst: 最大令牌数
-	Burst int64
+	Burst int64 `yaml:"burst"`
 	// Expiration: 令牌桶过期时间，默认1小时
-	Expiration time.Duration
+	Expiration time.Duration `yaml:"expiration"`
 }
 
 // NewDefaultConfig 创建默认配置
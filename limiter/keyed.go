@@ -0,0 +1,90 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Bucket 单个令牌桶，MemoryBucket与RedisBucket均实现该接口
+type Bucket interface {
+	// AllowN 尝试获取指定数量的令牌，返回值: 是否允许通过，剩余令牌数
+	AllowN(ctx context.Context, tokens int64) (bool, int64, error)
+	// Close 关闭令牌桶
+	Close() error
+}
+
+// KeyedLimiter 按限流键划分令牌桶的限流器，实现Limiter接口，可用于request.Config.RateLimiter等按键限流的场景
+// 每个键第一次使用时通过newBucket创建令牌桶，之后一直保留直到Close，适用于键数量有限的场景（如按主机、按租户）
+type KeyedLimiter struct {
+	newBucket func(key string) Bucket
+
+	mu      sync.Mutex
+	buckets map[string]Bucket
+}
+
+// NewKeyedLimiter 创建按键划分令牌桶的限流器
+// newBucket: 为键创建令牌桶，同一个键只调用一次
+func NewKeyedLimiter(newBucket func(key string) Bucket) *KeyedLimiter {
+	return &KeyedLimiter{
+		newBucket: newBucket,
+		buckets:   make(map[string]Bucket),
+	}
+}
+
+// NewKeyedMemoryBucket 创建按键划分的内存令牌桶限流器，每个键使用独立的MemoryBucket
+// rate: 每秒生成的令牌数
+// capacity: 最大令牌数
+func NewKeyedMemoryBucket(rate float64, capacity int64) *KeyedLimiter {
+	return NewKeyedLimiter(func(string) Bucket {
+		return NewMemoryBucket(rate, capacity)
+	})
+}
+
+// NewKeyedRedisBucket 创建按键划分的Redis令牌桶限流器，每个键使用独立的RedisBucket，限流键即Redis键（可通过WithKeyPrefix加前缀）
+func NewKeyedRedisBucket(client redis.UniversalClient, rate float64, capacity int64, opts ...RedisBucketOption) *KeyedLimiter {
+	return NewKeyedLimiter(func(key string) Bucket {
+		return NewRedisBucket(client, key, rate, capacity, opts...)
+	})
+}
+
+// bucket 返回key对应的令牌桶，不存在时创建
+func (l *KeyedLimiter) bucket(key string) Bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = l.newBucket(key)
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// Allow 判断key是否允许通过1个请求
+// 返回值: 是否允许通过，剩余令牌数
+func (l *KeyedLimiter) Allow(ctx context.Context, key string) (bool, int64, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN 判断key是否允许通过N个请求
+// 返回值: 是否允许通过，剩余令牌数
+func (l *KeyedLimiter) AllowN(ctx context.Context, key string, n int64) (bool, int64, error) {
+	return l.bucket(key).AllowN(ctx, n)
+}
+
+// Close 关闭所有已创建的令牌桶，之后再使用时重新创建
+func (l *KeyedLimiter) Close() error {
+	l.mu.Lock()
+	buckets := l.buckets
+	l.buckets = make(map[string]Bucket)
+	l.mu.Unlock()
+
+	var firstErr error
+	for _, b := range buckets {
+		if err := b.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
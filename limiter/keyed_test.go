@@ -0,0 +1,60 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKeyedMemoryBucket 测试每个键使用独立的令牌桶，Close后重新创建
+func TestKeyedMemoryBucket(t *testing.T) {
+	ctx := context.Background()
+	l := NewKeyedMemoryBucket(0.001, 2)
+	defer l.Close()
+
+	allowed, remaining, err := l.AllowN(ctx, "a", 2)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Zero(t, remaining)
+	allowed, _, err = l.Allow(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	// 其他键不受影响
+	allowed, remaining, err = l.Allow(ctx, "b")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int64(1), remaining)
+
+	assert.NoError(t, l.Close())
+	allowed, _, err = l.Allow(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestKeyedRedisBucket 测试限流键作为Redis键，键前缀对每个键生效
+func TestKeyedRedisBucket(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedisClient(t)
+	keys := []string{"app:test:keyed:a", "app:test:keyed:b"}
+	client.Del(ctx, keys...)
+	defer client.Del(ctx, keys...)
+
+	l := NewKeyedRedisBucket(client, 0.001, 1, WithKeyPrefix("app:"))
+	defer l.Close()
+
+	allowed, _, err := l.Allow(ctx, "test:keyed:a")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, _, err = l.Allow(ctx, "test:keyed:a")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	allowed, _, err = l.Allow(ctx, "test:keyed:b")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	n, err := client.Exists(ctx, keys...).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
@@ -0,0 +1,197 @@
+package limiter
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBucketClosed 限流器已关闭时AcquireN返回的错误
+var ErrBucketClosed = errors.New("limiter closed")
+
+// MemoryBucket 基于内存的令牌桶限流器
+// 与RedisBucket不同，AcquireN在令牌不足时排队等待，按到达顺序（FIFO）发放令牌，
+// 高并发下不会出现部分调用方一直抢不到令牌的情况。
+// 令牌和等待队列只存在于当前进程，多实例部署时各实例独立限流，不提供跨实例的公平性
+type MemoryBucket struct {
+	rate     float64
+	capacity int64
+
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+	// 等待中的AcquireN调用，元素为*bucketWaiter，队首最先获得令牌
+	waiters *list.List
+	// 队首等待者令牌补足时触发发放的定时器
+	timer  *time.Timer
+	closed bool
+}
+
+// bucketWaiter AcquireN的等待者
+type bucketWaiter struct {
+	n     int64
+	ready chan struct{}
+	// ready关闭后读取：nil表示已获得令牌，否则为关闭原因
+	err error
+}
+
+// NewMemoryBucket 创建一个新的内存令牌桶限流器，初始令牌数为capacity
+// rate: 每秒生成的令牌数
+// capacity: 最大令牌数
+func NewMemoryBucket(rate float64, capacity int64) *MemoryBucket {
+	return &MemoryBucket{
+		rate:       rate,
+		capacity:   capacity,
+		tokens:     float64(capacity),
+		lastRefill: time.Now(),
+		waiters:    list.New(),
+	}
+}
+
+// refill 按经过的时间补充令牌，调用方需持有锁
+func (b *MemoryBucket) refill() {
+	now := time.Now()
+	if b.rate > 0 {
+		b.tokens += now.Sub(b.lastRefill).Seconds() * b.rate
+		if b.tokens > float64(b.capacity) {
+			b.tokens = float64(b.capacity)
+		}
+	}
+	b.lastRefill = now
+}
+
+// Allow 尝试获取1个令牌
+func (b *MemoryBucket) Allow(ctx context.Context) (bool, error) {
+	allowed, _, err := b.AllowN(ctx, 1)
+	return allowed, err
+}
+
+// AllowN 尝试获取指定数量的令牌，不等待
+// 有AcquireN在排队时直接拒绝，不插队
+func (b *MemoryBucket) AllowN(ctx context.Context, tokens int64) (bool, int64, error) {
+	if tokens <= 0 {
+		return false, 0, errors.New("tokens must be greater than 0")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.waiters.Len() > 0 || b.tokens < float64(tokens) {
+		return false, int64(b.tokens), nil
+	}
+	b.tokens -= float64(tokens)
+	return true, int64(b.tokens), nil
+}
+
+// AcquireN 获取n个令牌，令牌不足时阻塞等待，多个调用方按到达顺序获得令牌
+// 排在前面的调用方需要的令牌较多时，后面的调用方即使需要的较少也会继续等待
+// ctx取消时放弃等待并返回ctx.Err()；n超过capacity时永远无法满足，直接返回错误
+func (b *MemoryBucket) AcquireN(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return errors.New("tokens must be greater than 0")
+	}
+	if n > b.capacity {
+		return fmt.Errorf("tokens %d exceed capacity %d", n, b.capacity)
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBucketClosed
+	}
+	b.refill()
+	if b.waiters.Len() == 0 && b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		b.mu.Unlock()
+		return nil
+	}
+	w := &bucketWaiter{n: n, ready: make(chan struct{})}
+	elem := b.waiters.PushBack(w)
+	if b.waiters.Len() == 1 {
+		b.dispatch()
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.err
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-w.ready:
+		// 取消的同时已获得令牌，归还后交给后面的等待者
+		if w.err == nil {
+			b.tokens += float64(n)
+			if b.tokens > float64(b.capacity) {
+				b.tokens = float64(b.capacity)
+			}
+			b.dispatch()
+		}
+	default:
+		head := b.waiters.Front() == elem
+		b.waiters.Remove(elem)
+		if head {
+			b.dispatch()
+		}
+	}
+	return ctx.Err()
+}
+
+// dispatch 按顺序向令牌已足够的等待者发放令牌，并为新的队首安排定时器，调用方需持有锁
+func (b *MemoryBucket) dispatch() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.refill()
+	for b.waiters.Len() > 0 {
+		elem := b.waiters.Front()
+		w := elem.Value.(*bucketWaiter)
+		if b.tokens < float64(w.n) {
+			if b.rate > 0 {
+				wait := time.Duration((float64(w.n) - b.tokens) / b.rate * float64(time.Second))
+				b.timer = time.AfterFunc(wait, b.onTimer)
+			}
+			return
+		}
+		b.tokens -= float64(w.n)
+		b.waiters.Remove(elem)
+		close(w.ready)
+	}
+}
+
+// onTimer 定时器到期后重新发放令牌
+func (b *MemoryBucket) onTimer() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.dispatch()
+	}
+}
+
+// Close 关闭限流器，等待中的AcquireN返回ErrBucketClosed
+func (b *MemoryBucket) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	for elem := b.waiters.Front(); elem != nil; elem = elem.Next() {
+		w := elem.Value.(*bucketWaiter)
+		w.err = ErrBucketClosed
+		close(w.ready)
+	}
+	b.waiters.Init()
+	return nil
+}
@@ -0,0 +1,87 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waiting 返回排队中的AcquireN数量
+func (b *MemoryBucket) waiting() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.waiters.Len()
+}
+
+// TestMemoryBucketAcquireNFIFO 测试AcquireN按到达顺序发放令牌，需要令牌较多的调用方不会被后来者插队
+func TestMemoryBucketAcquireNFIFO(t *testing.T) {
+	ctx := context.Background()
+	bucket := NewMemoryBucket(10, 3)
+	defer bucket.Close()
+
+	// 耗尽初始令牌，之后每个调用方都需要排队；第一个调用方需等待300ms，足够其余调用方依次入队
+	assert.NoError(t, bucket.AcquireN(ctx, 3))
+
+	counts := []int64{3, 1, 2, 1, 3, 1}
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i, n := range counts {
+		wg.Add(1)
+		go func(i int, n int64) {
+			defer wg.Done()
+			assert.NoError(t, bucket.AcquireN(ctx, n))
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i, n)
+		// 等上一个调用方进入队列后再启动下一个，保证到达顺序确定
+		assert.Eventually(t, func() bool { return bucket.waiting() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	// 有调用方排队时AllowN不插队
+	allowed, _, err := bucket.AllowN(ctx, 1)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, order)
+}
+
+// TestMemoryBucketAcquireNCancel 测试ctx取消时退出队列，后面的调用方继续获得令牌
+func TestMemoryBucketAcquireNCancel(t *testing.T) {
+	bucket := NewMemoryBucket(10, 2)
+	defer bucket.Close()
+	assert.NoError(t, bucket.AcquireN(context.Background(), 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- bucket.AcquireN(ctx, 2) }()
+	assert.Eventually(t, func() bool { return bucket.waiting() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+
+	start := time.Now()
+	assert.NoError(t, bucket.AcquireN(context.Background(), 1))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// 超过容量的请求永远无法满足
+	assert.Error(t, bucket.AcquireN(context.Background(), 3))
+}
+
+// TestMemoryBucketClose 测试关闭后等待中的AcquireN返回ErrBucketClosed
+func TestMemoryBucketClose(t *testing.T) {
+	bucket := NewMemoryBucket(0, 1)
+	assert.NoError(t, bucket.AcquireN(context.Background(), 1))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- bucket.AcquireN(context.Background(), 1) }()
+	assert.Eventually(t, func() bool { return bucket.waiting() == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, bucket.Close())
+	assert.ErrorIs(t, <-errCh, ErrBucketClosed)
+}
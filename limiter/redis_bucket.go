@@ -3,31 +3,70 @@ package limiter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/lwy110193/go_vendor/cache"
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisBucket 基于Redis的令牌桶限流器
 type RedisBucket struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	key        string
 	rate       float64
 	capacity   int64
+	script     *redis.Script
 	replenish  chan struct{}
 	stop       chan struct{}
 }
 
+// RedisBucketOption RedisBucket配置选项
+type RedisBucketOption func(*RedisBucket)
+
+// WithKeyPrefix 设置键前缀（如"myapp:"），令牌桶状态键带此前缀
+func WithKeyPrefix(prefix string) RedisBucketOption {
+	return func(b *RedisBucket) {
+		b.key = prefix + b.key
+	}
+}
+
+// StateEncoding 令牌桶状态在Redis中的存储格式，每个令牌桶只占用一个键
+// 旧版本使用key与key:last_refill两个字符串键，升级后不再读取，令牌桶从满容量开始，旧键24小时后自动过期
+type StateEncoding int
+
+const (
+	// StateEncodingHash 默认格式，hash的tokens与last_refill字段，可直接用HGETALL查看
+	StateEncodingHash StateEncoding = iota
+	// StateEncodingMsgpack 字符串键保存msgpack编码的状态，占用内存更少，依赖Redis内置的cmsgpack库
+	StateEncodingMsgpack
+)
+
+// WithStateEncoding 设置令牌桶状态的存储格式，同一个键的所有实例需使用相同的格式
+func WithStateEncoding(encoding StateEncoding) RedisBucketOption {
+	return func(b *RedisBucket) {
+		if encoding == StateEncodingMsgpack {
+			b.script = allowNMsgpackScript
+		} else {
+			b.script = allowNScript
+		}
+	}
+}
+
 // NewRedisBucket 创建一个新的Redis令牌桶限流器
-func NewRedisBucket(client *redis.Client, key string, rate float64, capacity int64) *RedisBucket {
+func NewRedisBucket(client redis.UniversalClient, key string, rate float64, capacity int64, opts ...RedisBucketOption) *RedisBucket {
 	bucket := &RedisBucket{
 		client:    client,
 		key:       key,
 		rate:      rate,
 		capacity:  capacity,
+		script:    allowNScript,
 		replenish: make(chan struct{}),
 		stop:      make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(bucket)
+	}
 
 	// 初始化Lua脚本
 	bucket.initLuaScripts()
@@ -35,50 +74,49 @@ func NewRedisBucket(client *redis.Client, key string, rate float64, capacity int
 	return bucket
 }
 
-// 初始化Lua脚本
-func (b *RedisBucket) initLuaScripts() {
-	// 定义获取令牌的Lua脚本
-	allowScript := `
+// allowNScript 获取多令牌的Lua脚本（StateEncodingHash），通过EVALSHA执行，脚本未缓存时自动回退为EVAL
+// 令牌数与上次补充时间保存在同一个hash中，一次HMGET原子读取
+var allowNScript = redis.NewScript(`
 	local rate = tonumber(ARGV[1])
 	local capacity = tonumber(ARGV[2])
 	local now = tonumber(ARGV[3])
 	local tokens = tonumber(ARGV[4])
 	local key = KEYS[1]
-	local lastRefillTime = key .. ":last_refill"
 
-	local last = tonumber(redis.call("get", lastRefillTime) or now)
+	local state = redis.call("hmget", key, "tokens", "last_refill")
+	local last = tonumber(state[2]) or now
 	local delta = (now - last) / 1000 * rate
-	local currentTokens = math.min(capacity, (tonumber(redis.call("get", key) or capacity) + delta))
+	local currentTokens = math.min(capacity, (tonumber(state[1]) or capacity) + delta)
+
+	local allowed = tokens <= currentTokens and tokens or 0
+	local remaining = currentTokens
 
-	local allowed = 0
-	if currentTokens >= tokens then
-		allowed = 1
-		currentTokens = currentTokens - tokens
+	if allowed > 0 then
+		remaining = currentTokens - allowed
 	end
 
-	redis.call("set", key, currentTokens)
-	redis.call("set", lastRefillTime, now)
+	redis.call("hset", key, "tokens", remaining, "last_refill", now)
 	redis.call("expire", key, 86400) -- 24小时过期
-	redis.call("expire", lastRefillTime, 86400)
 
-	return {allowed, currentTokens}
-	`
-
-	// 注册Lua脚本
-	b.client.ScriptLoad(context.Background(), allowScript)
+	return {allowed, remaining}
+	`)
 
-	// 定义获取多令牌的Lua脚本
-	allowNScript := `
+// allowNMsgpackScript 获取多令牌的Lua脚本（StateEncodingMsgpack），状态为msgpack编码的{令牌数, 上次补充时间}
+var allowNMsgpackScript = redis.NewScript(`
 	local rate = tonumber(ARGV[1])
 	local capacity = tonumber(ARGV[2])
 	local now = tonumber(ARGV[3])
 	local tokens = tonumber(ARGV[4])
 	local key = KEYS[1]
-	local lastRefillTime = key .. ":last_refill"
 
-	local last = tonumber(redis.call("get", lastRefillTime) or now)
+	local stored, last = capacity, now
+	local raw = redis.call("get", key)
+	if raw then
+		local state = cmsgpack.unpack(raw)
+		stored, last = state[1], state[2]
+	end
 	local delta = (now - last) / 1000 * rate
-	local currentTokens = math.min(capacity, (tonumber(redis.call("get", key) or capacity) + delta))
+	local currentTokens = math.min(capacity, stored + delta)
 
 	local allowed = tokens <= currentTokens and tokens or 0
 	local remaining = currentTokens
@@ -87,16 +125,27 @@ func (b *RedisBucket) initLuaScripts() {
 		remaining = currentTokens - allowed
 	end
 
-	redis.call("set", key, remaining)
-	redis.call("set", lastRefillTime, now)
-	redis.call("expire", key, 86400) -- 24小时过期
-	redis.call("expire", lastRefillTime, 86400)
+	redis.call("set", key, cmsgpack.pack({remaining, now}), "EX", 86400) -- 24小时过期
 
 	return {allowed, remaining}
-	`
+	`)
 
-	// 注册Lua脚本
-	b.client.ScriptLoad(context.Background(), allowNScript)
+// 初始化Lua脚本
+func (b *RedisBucket) initLuaScripts() {
+	// 预加载脚本，失败时由AllowN回退为EVAL
+	b.script.Load(context.Background(), b.client)
+}
+
+// Ping 检查Redis连接并预加载限流脚本，用于启动自检与健康检查
+// 连接错误的类型见cache.PingRedis
+func (b *RedisBucket) Ping(ctx context.Context) error {
+	if err := cache.PingRedis(ctx, b.client); err != nil {
+		return err
+	}
+	if err := b.script.Load(ctx, b.client).Err(); err != nil {
+		return fmt.Errorf("load rate limit script: %w", err)
+	}
+	return nil
 }
 
 // Allow 尝试获取1个令牌
@@ -111,36 +160,8 @@ func (b *RedisBucket) AllowN(ctx context.Context, tokens int64) (bool, int64, er
 		return false, 0, errors.New("tokens must be greater than 0")
 	}
 
-	// 定义获取多令牌的Lua脚本
-	allowNScript := `
-	local rate = tonumber(ARGV[1])
-	local capacity = tonumber(ARGV[2])
-	local now = tonumber(ARGV[3])
-	local tokens = tonumber(ARGV[4])
-	local key = KEYS[1]
-	local lastRefillTime = key .. ":last_refill"
-
-	local last = tonumber(redis.call("get", lastRefillTime) or now)
-	local delta = (now - last) / 1000 * rate
-	local currentTokens = math.min(capacity, (tonumber(redis.call("get", key) or capacity) + delta))
-
-	local allowed = tokens <= currentTokens and tokens or 0
-	local remaining = currentTokens
-
-	if allowed > 0 then
-		remaining = currentTokens - allowed
-	end
-
-	redis.call("set", key, remaining)
-	redis.call("set", lastRefillTime, now)
-	redis.call("expire", key, 86400) -- 24小时过期
-	redis.call("expire", lastRefillTime, 86400)
-
-	return {allowed, remaining}
-	`
-
 	now := time.Now().UnixNano() / int64(time.Millisecond)
-	res, err := b.client.Eval(ctx, allowNScript, []string{b.key}, b.rate, b.capacity, now, tokens).Result()
+	res, err := b.script.Run(ctx, b.client, []string{b.key}, b.rate, b.capacity, now, tokens).Result()
 	if err != nil {
 		return false, 0, err
 	}
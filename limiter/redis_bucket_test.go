@@ -2,13 +2,40 @@ package limiter
 
 import (
 	"context"
+	"net"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/lwy110193/go_vendor/cache"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
+// newTestRedisClient 创建测试用Redis客户端，地址可通过REDIS_ADDR环境变量指定
+// Redis不可用时跳过测试
+func newTestRedisClient(t *testing.T) *redis.Client {
+	t.Helper()
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "192.168.3.42:6379"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: "redis_MK8zA6",
+		DB:       0,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		t.Skipf("redis %s not available: %v", addr, err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
 // TestRedisBucketAllow 测试基本的令牌获取功能
 func TestRedisBucketAllow(t *testing.T) {
 	ctx := context.Background()
@@ -111,4 +138,128 @@ func TestRedisBucketExpiration(t *testing.T) {
 	// 2秒后应该补充了约20个令牌，但受容量限制，最多20个
 	// 减去之前剩余的15个，应该新增了5个，所以现在剩余应该是15+5-10=10
 	assert.True(t, remaining <= 20)
-}
\ No newline at end of file
+}
+
+// TestRedisBucketPing 测试健康检查与脚本预加载
+func TestRedisBucketPing(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedisClient(t)
+	bucket := NewRedisBucket(client, "test:bucket:ping", 10, 20)
+	defer bucket.Close()
+
+	assert.NoError(t, client.ScriptFlush(ctx).Err())
+	assert.NoError(t, bucket.Ping(ctx))
+	loaded, err := client.ScriptExists(ctx, allowNScript.Hash()).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true}, loaded)
+
+	// 脚本缓存被清空后AllowN仍可执行
+	assert.NoError(t, client.ScriptFlush(ctx).Err())
+	client.Del(ctx, "test:bucket:ping", "test:bucket:ping:last_refill")
+	allowed, err := bucket.Allow(ctx)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestRedisBucketPingUnreachable 测试地址不可达时的健康检查
+func TestRedisBucketPingUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	bucket := NewRedisBucket(client, "test:bucket:unreachable", 10, 20)
+	defer bucket.Close()
+
+	assert.ErrorIs(t, bucket.Ping(context.Background()), cache.ErrRedisUnreachable)
+}
+
+// TestRedisBucketKeyPrefix 测试键前缀及不同前缀间的隔离
+func TestRedisBucketKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedisClient(t)
+	key := "test:bucket:prefix"
+	keys := []string{"app_a:" + key, "app_b:" + key}
+	client.Del(ctx, keys...)
+	defer client.Del(ctx, keys...)
+
+	bucketA := NewRedisBucket(client, key, 0.001, 2, WithKeyPrefix("app_a:"))
+	defer bucketA.Close()
+	bucketB := NewRedisBucket(client, key, 0.001, 2, WithKeyPrefix("app_b:"))
+	defer bucketB.Close()
+
+	allowed, _, err := bucketA.AllowN(ctx, 2)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = bucketA.Allow(ctx)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	n, err := client.Exists(ctx, "app_a:"+key).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = client.Exists(ctx, key).Result()
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	// 另一前缀的令牌桶不受影响
+	allowed, err = bucketB.Allow(ctx)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestRedisBucketSingleKey 测试每个令牌桶只占用一个Redis键，各存储格式下限流行为一致
+func TestRedisBucketSingleKey(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedisClient(t)
+
+	cases := []struct {
+		name     string
+		encoding StateEncoding
+		keyType  string
+	}{
+		{"hash", StateEncodingHash, "hash"},
+		{"msgpack", StateEncodingMsgpack, "string"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			key := "test:bucket:single:" + tc.name
+			client.Del(ctx, key)
+			defer client.Del(ctx, key)
+
+			bucket := NewRedisBucket(client, key, 0.001, 5, WithStateEncoding(tc.encoding))
+			defer bucket.Close()
+
+			allowed, remaining, err := bucket.AllowN(ctx, 3)
+			if err != nil && tc.encoding == StateEncodingMsgpack {
+				t.Skipf("redis does not support cmsgpack: %v", err)
+			}
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+			assert.Equal(t, int64(2), remaining)
+
+			allowed, remaining, err = bucket.AllowN(ctx, 3)
+			assert.NoError(t, err)
+			assert.False(t, allowed)
+			assert.Equal(t, int64(2), remaining)
+
+			allowed, err = bucket.Allow(ctx)
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+
+			keys, err := client.Keys(ctx, key+"*").Result()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{key}, keys)
+			keyType, err := client.Type(ctx, key).Result()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.keyType, keyType)
+			ttl, err := client.TTL(ctx, key).Result()
+			assert.NoError(t, err)
+			assert.Greater(t, ttl, time.Hour)
+		})
+	}
+}
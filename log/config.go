@@ -3,33 +3,41 @@ package log
 // Config 日志配置结构体
 type Config struct {
 	// Level 设置日志的最低记录级别
-	Level Level
+	Level Level `yaml:"level"`
 	// StdoutEnable 是否输出到标准输出，设置为true时，日志会输出到标准输出，和文件可以同时存在
-	StdoutEnable bool
+	StdoutEnable bool `yaml:"stdout_enable"`
 	// FileOutEnable 是否输出到文件，设置为true时，日志会输出到文件，和标准输出可以同时存在
-	FileOutEnable bool
+	FileOutEnable bool `yaml:"file_out_enable"`
 	// OutputDir 指定日志文件输出目录，为空则输出到标准输出
-	OutputDir string
+	OutputDir string `yaml:"output_dir"`
 	// Filename 指定日志文件名，默认为"app.log"
-	Filename string
+	Filename string `yaml:"filename"`
 	// ErrorSperate 是否将错误日志与正常日志分离开来，设置为true时，错误日志会输出到单独的文件
-	ErrorSperate bool
+	ErrorSperate bool `yaml:"error_sperate"`
 	// ErrorFilename 指定错误日志文件名，默认为Filename+"_error"
-	ErrorFilename string
-	// MaxSize 单个日志文件的最大大小（MB），默认为100MB
-	MaxSize int
-	// MaxAge 日志文件的最大保留天数，默认为7天
-	MaxAge int
-	// ByDate 是否按日期分文件，设置为true时，日志文件名会包含日期
-	ByDate bool
+	ErrorFilename string `yaml:"error_filename"`
+	// MaxSize 单个日志文件的最大大小（MB），默认为100MB，超过后轮转为带序号的备份，如app.1.log
+	MaxSize int `yaml:"max_size"`
+	// MaxAge 备份日志文件的最大保留天数，默认为7天
+	MaxAge int `yaml:"max_age"`
+	// MaxBackups 保留的备份日志文件个数，0表示不限制（仍受MaxAge限制）
+	MaxBackups int `yaml:"max_backups"`
+	// ByDate 是否按日期分文件，设置为true时，日志文件名会包含日期，跨天自动切换
+	// 可与MaxSize同时生效：同一天内超过大小时在日期后追加序号，如app.20240102.1.log；
+	// 前一天的文件同样计入MaxBackups和MaxAge
+	ByDate bool `yaml:"by_date"`
 	// Development 是否为开发模式，开发模式下日志更易读
-	Development bool
+	Development bool `yaml:"development"`
 	// Encoding 日志编码方式，json或console
-	Encoding string
+	Encoding string `yaml:"encoding"`
 	// BufferSize 设置日志缓冲区大小（字节），0表示使用默认值
-	BufferSize int
+	BufferSize int `yaml:"buffer_size"`
 	// FlushInterval 设置自动刷新间隔（秒），0表示不自动刷新
-	FlushInterval int
+	FlushInterval int `yaml:"flush_interval"`
 	// FlushOnWrite 设置是否在每次写入后立即刷新，适用于关键日志
-	FlushOnWrite bool
+	FlushOnWrite bool `yaml:"flush_on_write"`
+	// StacktraceLevel 记录堆栈的最低级别，为nil时默认为ERROR
+	StacktraceLevel *Level `yaml:"stacktrace_level"`
+	// DisableStacktrace 是否完全关闭堆栈记录，高频可恢复错误场景下可关闭以降低开销
+	DisableStacktrace bool `yaml:"disable_stacktrace"`
 }
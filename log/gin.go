@@ -0,0 +1,64 @@
+package log
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lwy110193/go_vendor/ctxkey"
+)
+
+// GinAccessLog 返回Gin访问日志中间件，每个请求记录一条结构化日志
+// 字段: method、path、status、latency、client_ip、size(响应体字节数)，有链路信息时附带trace_id
+// 级别按状态码区分: 5xx为Error，4xx为Warn，其余为Info
+// skipPaths中的路径（如"/metrics"、"/healthz"）不记录，按请求路径精确匹配
+// 与tracer.GinTraceMiddleware同时使用时应先注册本中间件，以便记录trace_id
+func GinAccessLog(logger *Logger, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if _, ok := skip[path]; ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		keysAndValues := []interface{}{
+			"method", c.Request.Method,
+			"path", path,
+			"status", status,
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"size", size,
+		}
+		// 中间件链中替换过的请求上下文，有span时由日志方法添加trace_id，否则使用ctxkey中的trace_id
+		ctx := c.Request.Context()
+		if len(logger.addTraceContext(ctx)) == 0 {
+			if traceID, ok := ctxkey.TraceID(ctx); ok {
+				keysAndValues = append(keysAndValues, "trace_id", traceID)
+			}
+		}
+		if len(c.Errors) > 0 {
+			keysAndValues = append(keysAndValues, "errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			logger.Errorwc(ctx, "access", keysAndValues...)
+		case status >= 400:
+			logger.Warnwc(ctx, "access", keysAndValues...)
+		default:
+			logger.Infowc(ctx, "access", keysAndValues...)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package log_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lwy110193/go_vendor/ctxkey"
+	"github.com/lwy110193/go_vendor/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TestGinAccessLog 测试每个请求记录一条访问日志，级别按状态码区分，跳过指定路径
+func TestGinAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	logger, err := log.New(log.Config{
+		Level:             log.DEBUG,
+		FileOutEnable:     true,
+		OutputDir:         dir,
+		Filename:          "access.log",
+		DisableStacktrace: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+
+	router := gin.New()
+	router.Use(log.GinAccessLog(logger, "/healthz"))
+	router.Use(func(c *gin.Context) {
+		ctx := ctxkey.WithTraceID(c.Request.Context(), "legacy-trace")
+		if c.Request.URL.Path == "/traced" {
+			ctx, _ = tp.Tracer("log_test").Start(ctx, "traced")
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+	router.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "hello") })
+	router.GET("/traced", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/boom", func(c *gin.Context) { c.String(http.StatusInternalServerError, "boom") })
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/ok", "/missing", "/boom", "/healthz", "/traced"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	logger.Close()
+
+	lines := readLogLines(t, filepath.Join(dir, "access.log"))
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 access log entries, got %d: %v", len(lines), lines)
+	}
+	want := []struct {
+		path   string
+		status float64
+		level  string
+		size   float64
+	}{
+		{"/ok", 200, "INFO", 5},
+		// 未匹配路由时gin在中间件链结束后才写入默认的404响应体
+		{"/missing", 404, "WARN", 0},
+		{"/boom", 500, "ERROR", 4},
+		{"/traced", 204, "INFO", 0},
+	}
+	for i, w := range want {
+		line := lines[i]
+		if line["msg"] != "access" || line["method"] != "GET" || line["path"] != w.path ||
+			line["status"] != w.status || line["level"] != w.level || line["size"] != w.size ||
+			line["client_ip"] != "10.0.0.1" || line["latency"] == nil {
+			t.Errorf("Unexpected access log entry for %s: %v", w.path, line)
+		}
+	}
+	if lines[0]["trace_id"] != "legacy-trace" {
+		t.Errorf("Expected trace_id from ctxkey, got %v", lines[0]["trace_id"])
+	}
+	if id, _ := lines[3]["trace_id"].(string); len(id) != 32 || lines[3]["span_id"] == nil {
+		t.Errorf("Expected trace_id from span, got %v", lines[3])
+	}
+}
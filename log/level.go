@@ -1,6 +1,7 @@
 package log
 
 import (
+	"fmt"
 	"strings"
 
 	"go.uber.org/zap/zapcore"
@@ -41,21 +42,29 @@ func (l Level) String() string {
 	}
 }
 
-// ParseLevel 根据字符串解析日志级别
+// ParseLevel 根据字符串解析日志级别，无法识别时返回INFO
 func ParseLevel(levelStr string) Level {
+	if level, ok := lookupLevel(levelStr); ok {
+		return level
+	}
+	return INFO // 默认返回INFO级别
+}
+
+// lookupLevel 根据字符串查找日志级别，不区分大小写
+func lookupLevel(levelStr string) (Level, bool) {
 	switch strings.ToUpper(levelStr) {
 	case "DEBUG":
-		return DEBUG
+		return DEBUG, true
 	case "INFO":
-		return INFO
+		return INFO, true
 	case "WARNING", "WARN":
-		return WARNING
+		return WARNING, true
 	case "ERROR":
-		return ERROR
+		return ERROR, true
 	case "FATAL":
-		return FATAL
+		return FATAL, true
 	default:
-		return INFO // 默认返回INFO级别
+		return INFO, false
 	}
 }
 
@@ -75,4 +84,15 @@ func (l Level) ToZapLevel() zapcore.Level {
 	default:
 		return zapcore.InfoLevel
 	}
-}
\ No newline at end of file
+}
+
+// UnmarshalText 实现encoding.TextUnmarshaler接口，支持从YAML/环境变量中解析日志级别，如 "debug"、"warn"
+// 无法识别的级别返回错误，避免拼写错误被当作INFO静默接受
+func (l *Level) UnmarshalText(text []byte) error {
+	level, ok := lookupLevel(string(text))
+	if !ok {
+		return fmt.Errorf("unknown log level %q", text)
+	}
+	*l = level
+	return nil
+}
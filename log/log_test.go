@@ -17,7 +17,7 @@ func TestLogger(t *testing.T) {
 		Level:         log.INFO,
 		Development:   false,
 		ErrorSperate:  true,
-		OutputDir:     "./logs",
+		OutputDir:     t.TempDir(),
 		Filename:      "custom_trace.log",
 		MaxSize:       100,
 		MaxAge:        7,
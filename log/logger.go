@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/lwy110193/go_vendor/utils"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -16,8 +18,24 @@ type Logger struct {
 	logger        *zap.Logger
 	sugar         *zap.SugaredLogger
 	config        Config
-	flushTicker   *time.Ticker // 定时刷新器
-	stopFlushChan chan bool    // 停止刷新通道
+	level         *levelControl // 当前级别，With/Named派生的日志记录器共享
+	flushTicker   *time.Ticker  // 定时刷新器
+	stopFlushChan chan bool     // 停止刷新通道
+}
+
+// levelControl 运行时可调整的日志级别
+type levelControl struct {
+	atomic  zap.AtomicLevel
+	mu      sync.Mutex
+	current Level
+	prior   Level       // BoostLevel之前的级别，到期后恢复
+	boost   *time.Timer // 进行中的BoostLevel，为nil表示未提升
+}
+
+// set 设置当前级别，调用方需持有锁
+func (c *levelControl) set(level Level) {
+	c.current = level
+	c.atomic.SetLevel(level.ToZapLevel())
 }
 
 // DefaultConfig 返回默认的日志配置
@@ -32,6 +50,7 @@ func DefaultConfig() Config {
 		ErrorFilename: "",     // 默认错误日志文件名，如error.log
 		MaxSize:       100,    // 默认100MB
 		MaxAge:        7,      // 默认保留7天
+		MaxBackups:    0,      // 默认不限制备份个数
 		ByDate:        false,  // 默认按日期分文件
 		Development:   false,  // 默认生产模式
 		Encoding:      "json", // 默认JSON格式
@@ -58,21 +77,12 @@ func (e CustomLevelEnabler) Enabled(level zapcore.Level) bool {
 // New 创建一个新的日志记录器
 func New(config Config) (*Logger, error) {
 	// 如果配置未指定，则使用默认配置
-	if config.Filename == "" {
-		config.Filename = "app.log"
-	}
-	if config.ErrorFilename == "" {
-		config.ErrorFilename = "error_" + config.Filename
-	}
-	if config.MaxSize <= 0 {
-		config.MaxSize = 100
-	}
-	if config.MaxAge <= 0 {
-		config.MaxAge = 7
-	}
-	if config.Encoding == "" {
-		config.Encoding = "json"
-	}
+	notPositive := func(n int) bool { return n <= 0 }
+	config.Filename = utils.Coalesce(config.Filename, "app.log")
+	config.ErrorFilename = utils.Coalesce(config.ErrorFilename, "error_"+config.Filename)
+	config.MaxSize = utils.CoalesceFunc(notPositive, config.MaxSize, 100)
+	config.MaxAge = utils.CoalesceFunc(notPositive, config.MaxAge, 7)
+	config.Encoding = utils.Coalesce(config.Encoding, "json")
 
 	// 设置日志级别
 	atomicLevel := zap.NewAtomicLevelAt(config.Level.ToZapLevel())
@@ -121,24 +131,16 @@ func New(config Config) (*Logger, error) {
 			return nil, err
 		}
 
-		// 生成正常日志文件名
-		normalFilename := generateFilename(config)
-		normalFilePath := config.OutputDir + "/" + normalFilename
-
 		// 创建正常日志文件writer
-		normalWriter, err := newLogWriter(normalFilePath, config.MaxSize, config.MaxAge)
+		normalWriter, err := newLogWriter(config, config.Filename)
 		if err != nil {
 			return nil, err
 		}
 
 		if config.ErrorSperate {
 			// 如果开启错误日志分离
-			// 生成错误日志文件名
-			errorFilename := generateFilenameError(config)
-			errorFilePath := config.OutputDir + "/" + errorFilename
-
 			// 创建错误日志文件writer
-			errorWriter, err := newLogWriter(errorFilePath, config.MaxSize, config.MaxAge)
+			errorWriter, err := newLogWriter(config, config.ErrorFilename)
 			if err != nil {
 				return nil, err
 			}
@@ -192,9 +194,13 @@ func New(config Config) (*Logger, error) {
 	core := zapcore.NewTee(cores...)
 
 	// 添加caller和stacktrace
-	options := []zap.Option{
-		zap.AddCaller(),
-		zap.AddStacktrace(zapcore.ErrorLevel),
+	options := []zap.Option{zap.AddCaller()}
+	if !config.DisableStacktrace {
+		stacktraceLevel := zapcore.ErrorLevel
+		if config.StacktraceLevel != nil {
+			stacktraceLevel = config.StacktraceLevel.ToZapLevel()
+		}
+		options = append(options, zap.AddStacktrace(stacktraceLevel))
 	}
 
 	// 创建logger
@@ -204,6 +210,7 @@ func New(config Config) (*Logger, error) {
 		logger:        zapLogger,
 		sugar:         zapLogger.Sugar(),
 		config:        config,
+		level:         &levelControl{atomic: atomicLevel, current: config.Level},
 		stopFlushChan: make(chan bool),
 	}
 
@@ -215,43 +222,54 @@ func New(config Config) (*Logger, error) {
 	return logger, nil
 }
 
-// generateFilenameError 根据配置生成错误日志文件名
-func generateFilenameError(config Config) string {
-	if config.ByDate {
-		// 如果启用按日期分文件，添加日期后缀
-		dateStr := time.Now().Format("20060102")
-		// 检查文件名是否已有扩展名
-		baseName := config.ErrorFilename
-		extension := ""
-		if dotIndex := len(baseName) - 4; dotIndex > 0 && baseName[dotIndex:] == ".log" {
-			baseName = baseName[:dotIndex]
-			extension = ".log"
-		}
-		return fmt.Sprintf("%s.%s%s", baseName, dateStr, extension)
-	}
-	return config.ErrorFilename
+// newLogWriter 创建一个日志文件writer，按配置进行大小轮转、按日期分文件及备份清理
+func newLogWriter(config Config, filename string) (zapcore.WriteSyncer, error) {
+	return newRotateWriter(config.OutputDir, filename, config.ByDate, config.MaxSize, config.MaxAge, config.MaxBackups)
 }
 
-// newLogWriter 创建一个日志文件writer
-func newLogWriter(filePath string, maxSize, maxAge int) (zapcore.WriteSyncer, error) {
-	// 这里可以添加日志文件轮转逻辑
-	// 目前简单实现，直接返回文件writer
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, err
-	}
-	return zapcore.AddSync(file), nil
-}
-
-// SetLevel 设置日志记录的最低级别
+// SetLevel 设置日志记录的最低级别，进行中的BoostLevel随之取消，不再恢复
 func (l *Logger) SetLevel(level Level) {
-	l.logger.Core().Enabled(level.ToZapLevel())
+	l.level.mu.Lock()
+	defer l.level.mu.Unlock()
+	if l.level.boost != nil {
+		l.level.boost.Stop()
+		l.level.boost = nil
+	}
+	l.level.set(level)
 }
 
 // GetLevel 获取当前日志记录的最低级别
 func (l *Logger) GetLevel() Level {
-	// zap没有直接获取当前级别的方法，这里返回配置中的级别
-	return l.config.Level
+	l.level.mu.Lock()
+	defer l.level.mu.Unlock()
+	return l.level.current
+}
+
+// BoostLevel 临时将日志级别调整为level（通常为DEBUG，便于线上排查），d之后自动恢复为调整前的级别
+// 上一次调整尚未到期时再次调用，以新的level和d为准，到期后仍恢复为第一次调整前的级别
+func (l *Logger) BoostLevel(level Level, d time.Duration) {
+	c := l.level
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.boost != nil {
+		c.boost.Stop()
+	} else {
+		c.prior = c.current
+	}
+	c.set(level)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// 已被新的BoostLevel或SetLevel取代
+		if c.boost != timer {
+			return
+		}
+		c.boost = nil
+		c.set(c.prior)
+	})
+	c.boost = timer
 }
 
 // startAutoFlush 启动自动刷新
@@ -374,6 +392,22 @@ func (l *Logger) Fatalwc(ctx context.Context, msg string, keysAndValues ...inter
 	}
 }
 
+// LogResult 以统一格式记录一次操作的结果，用于数据库、缓存、HTTP等操作日志
+// 消息为op，附带op、status(ok/error)、duration(自start起的耗时)字段，出错时附带error字段；
+// 成功记录为Info级别，出错记录为Error级别，ctx中有span时自动添加traceid和spanid
+// 用法: defer func(start time.Time) { logger.LogResult(ctx, "db.query", start, err, "table", "users") }(time.Now())
+func (l *Logger) LogResult(ctx context.Context, op string, start time.Time, err error, fields ...interface{}) {
+	keysAndValues := make([]interface{}, 0, len(fields)+8)
+	keysAndValues = append(keysAndValues, "op", op, "duration", time.Since(start))
+	if err != nil {
+		keysAndValues = append(keysAndValues, "status", "error", "error", err.Error())
+		l.Errorwc(ctx, op, append(keysAndValues, fields...)...)
+		return
+	}
+	keysAndValues = append(keysAndValues, "status", "ok")
+	l.Infowc(ctx, op, append(keysAndValues, fields...)...)
+}
+
 // Debug 记录调试级别日志
 func (l *Logger) Debugf(format string, args ...interface{}) {
 	l.sugar.Debugf(format, args...)
@@ -493,6 +527,7 @@ func (l *Logger) With(keysAndValues ...interface{}) *Logger {
 		logger: l.sugar.With(keysAndValues...).Desugar(),
 		sugar:  l.sugar.With(keysAndValues...),
 		config: l.config,
+		level:  l.level,
 	}
 }
 
@@ -502,6 +537,7 @@ func (l *Logger) Named(name string) *Logger {
 		logger: l.logger.Named(name),
 		sugar:  l.sugar.Named(name),
 		config: l.config,
+		level:  l.level,
 	}
 }
 
@@ -531,19 +567,19 @@ func (l *Logger) withTrace(traceID, spanID string) *zap.SugaredLogger {
 	)
 }
 
-// generateFilename 根据配置生成日志文件名
-func generateFilename(config Config) string {
-	if config.ByDate {
-		// 如果启用按日期分文件，添加日期后缀
-		dateStr := time.Now().Format("20060102")
-		// 检查文件名是否已有扩展名
-		baseName := config.Filename
-		extension := ""
-		if dotIndex := len(baseName) - 4; dotIndex > 0 && baseName[dotIndex:] == ".log" {
-			baseName = baseName[:dotIndex]
-			extension = ".log"
-		}
-		return fmt.Sprintf("%s.%s%s", baseName, dateStr, extension)
+// dateLayout 按日期分文件时文件名中的日期格式
+const dateLayout = "20060102"
+
+// datedFilename 在文件名的.log扩展名前插入日期，如app.log -> app.20240102.log
+func datedFilename(filename, date string) string {
+	base, ext := splitLogExt(filename)
+	return fmt.Sprintf("%s.%s%s", base, date, ext)
+}
+
+// splitLogExt 拆分文件名与.log扩展名，没有.log扩展名时ext为空
+func splitLogExt(filename string) (base, ext string) {
+	if dotIndex := len(filename) - 4; dotIndex > 0 && filename[dotIndex:] == ".log" {
+		return filename[:dotIndex], ".log"
 	}
-	return config.Filename
+	return filename, ""
 }
@@ -0,0 +1,272 @@
+package log_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/log"
+)
+
+// readLogLines 读取JSON格式的日志文件，每行解析为一个map
+func readLogLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Unmarshal() error = %v, line = %s", err, scanner.Bytes())
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// TestStacktraceLevel 测试堆栈记录级别的配置与关闭
+func TestStacktraceLevel(t *testing.T) {
+	warn := log.WARNING
+	tests := []struct {
+		name      string
+		config    log.Config
+		wantStack map[string]bool // 按日志级别期望是否包含stacktrace
+	}{
+		{"default", log.Config{}, map[string]bool{"WARN": false, "ERROR": true}},
+		{"warning", log.Config{StacktraceLevel: &warn}, map[string]bool{"WARN": true, "ERROR": true}},
+		{"disabled", log.Config{DisableStacktrace: true}, map[string]bool{"WARN": false, "ERROR": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := tt.config
+			config.FileOutEnable = true
+			config.OutputDir = dir
+			config.Filename = "app.log"
+			config.FlushOnWrite = true
+			logger, err := log.New(config)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			logger.Warnw("warn message")
+			logger.Errorw("error message")
+			logger.Close()
+
+			lines := readLogLines(t, filepath.Join(dir, "app.log"))
+			if len(lines) != 2 {
+				t.Fatalf("got %d lines, want 2", len(lines))
+			}
+			for _, line := range lines {
+				level, _ := line["level"].(string)
+				_, hasStack := line["stacktrace"]
+				if hasStack != tt.wantStack[level] {
+					t.Errorf("level %s: has stacktrace = %v, want %v", level, hasStack, tt.wantStack[level])
+				}
+			}
+		})
+	}
+}
+
+// TestLogResult 测试操作结果日志的级别与字段
+func TestLogResult(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := log.New(log.Config{
+		FileOutEnable:     true,
+		OutputDir:         dir,
+		Filename:          "app.log",
+		FlushOnWrite:      true,
+		DisableStacktrace: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	start := time.Now().Add(-1500 * time.Millisecond)
+	logger.LogResult(ctx, "db.query", start, nil, "table", "users")
+	logger.LogResult(ctx, "cache.get", start, errors.New("connection refused"))
+	logger.Close()
+
+	lines := readLogLines(t, filepath.Join(dir, "app.log"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	want := []struct {
+		level, op, status string
+	}{
+		{"INFO", "db.query", "ok"},
+		{"ERROR", "cache.get", "error"},
+	}
+	for i, w := range want {
+		line := lines[i]
+		if line["level"] != w.level || line["msg"] != w.op || line["op"] != w.op || line["status"] != w.status {
+			t.Errorf("line %d = %v, want level=%s op=%s status=%s", i, line, w.level, w.op, w.status)
+		}
+		duration, ok := line["duration"].(float64)
+		if !ok || duration < 1.5 {
+			t.Errorf("line %d duration = %v, want >= 1.5s", i, line["duration"])
+		}
+	}
+	if lines[0]["table"] != "users" {
+		t.Errorf("table field = %v, want users", lines[0]["table"])
+	}
+	if _, ok := lines[0]["error"]; ok {
+		t.Errorf("success line should not have error field")
+	}
+	if lines[1]["error"] != "connection refused" {
+		t.Errorf("error field = %v, want connection refused", lines[1]["error"])
+	}
+}
+
+// TestRotateMaxBackups 测试按大小轮转后只保留MaxBackups个备份，并与ByDate同时生效
+func TestRotateMaxBackups(t *testing.T) {
+	payload := strings.Repeat("x", 64<<10)
+	for _, byDate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("by_date=%v", byDate), func(t *testing.T) {
+			dir := t.TempDir()
+			logger, err := log.New(log.Config{
+				FileOutEnable: true,
+				OutputDir:     dir,
+				Filename:      "app.log",
+				MaxSize:       1,
+				MaxBackups:    2,
+				ByDate:        byDate,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			// 约5MB，触发多次轮转
+			for i := 0; i < 80; i++ {
+				logger.Infow("rotate", "payload", payload)
+			}
+			logger.Close()
+
+			current := "app.log"
+			if byDate {
+				current = "app." + time.Now().Format("20060102") + ".log"
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			// 当前文件加最新的两个备份，最早的备份已被删除
+			if len(names) != 3 {
+				t.Fatalf("files = %v, want current file and 2 backups", names)
+			}
+			base := strings.TrimSuffix(current, ".log")
+			for _, name := range names {
+				if name == current {
+					continue
+				}
+				if !strings.HasPrefix(name, base+".") || name == base+".1.log" || name == base+".2.log" {
+					t.Errorf("unexpected file %s in %v", name, names)
+				}
+			}
+			for _, name := range names {
+				info, _ := os.Stat(filepath.Join(dir, name))
+				if info.Size() > 1<<20 {
+					t.Errorf("%s size = %d, exceeds MaxSize", name, info.Size())
+				}
+			}
+			if lines := readLogLines(t, filepath.Join(dir, current)); len(lines) == 0 {
+				t.Error("current log file is empty")
+			}
+		})
+	}
+}
+
+// TestRotateIgnoresDatedFiles 测试未开启ByDate时，遗留的按日期命名的文件不影响备份序号
+func TestRotateIgnoresDatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.20240102.log"), []byte("old\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	logger, err := log.New(log.Config{
+		FileOutEnable: true,
+		OutputDir:     dir,
+		Filename:      "app.log",
+		MaxSize:       1,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	payload := strings.Repeat("x", 64<<10)
+	for i := 0; i < 24; i++ {
+		logger.Infow("rotate", "payload", payload)
+	}
+	logger.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "app.1.log")); err != nil {
+		t.Errorf("backup app.1.log not found: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app.20240103.log")); err == nil {
+		t.Error("backup was numbered after the dated file")
+	}
+}
+
+// TestBoostLevel 测试临时提升为DEBUG后自动恢复，重叠调用恢复为最初的级别
+func TestBoostLevel(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := log.New(log.Config{
+		Level:         log.INFO,
+		FileOutEnable: true,
+		OutputDir:     dir,
+		Filename:      "app.log",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	child := logger.Named("child")
+
+	logger.BoostLevel(log.DEBUG, 50*time.Millisecond)
+	if got := logger.GetLevel(); got != log.DEBUG {
+		t.Errorf("GetLevel() = %v, want DEBUG", got)
+	}
+	child.Debugw("during boost")
+
+	// 重叠调用延长窗口，到期后恢复为INFO而不是DEBUG
+	logger.BoostLevel(log.DEBUG, 150*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if got := logger.GetLevel(); got != log.DEBUG {
+		t.Errorf("GetLevel() after first window = %v, want DEBUG", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for logger.GetLevel() != log.INFO && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := logger.GetLevel(); got != log.INFO {
+		t.Fatalf("GetLevel() after boost = %v, want INFO", got)
+	}
+	child.Debugw("after boost")
+	logger.Close()
+
+	lines := readLogLines(t, filepath.Join(dir, "app.log"))
+	if len(lines) != 1 || lines[0]["msg"] != "during boost" {
+		t.Errorf("Expected only the debug line written during boost, got %v", lines)
+	}
+
+	// SetLevel取消进行中的提升
+	logger.BoostLevel(log.DEBUG, 20*time.Millisecond)
+	logger.SetLevel(log.WARNING)
+	time.Sleep(50 * time.Millisecond)
+	if got := logger.GetLevel(); got != log.WARNING {
+		t.Errorf("GetLevel() after SetLevel = %v, want WARNING", got)
+	}
+}
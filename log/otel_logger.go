@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	stdlog "log"
+	"sync"
 
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
@@ -107,6 +108,11 @@ func NewCustomExporter(options ...CustomExporterOption) (*CustomExporter, error)
 
 type CustomExporter struct {
 	logWriter LogWriter
+	// bufferSize 缓冲的span数量，达到该数量时写入，0表示不缓冲
+	bufferSize int
+	mu         sync.Mutex
+	buffer     [][]interface{}
+	stopped    bool
 }
 
 // CustomExporterOption 是自定义导出器的选项
@@ -126,8 +132,27 @@ func WithLocalLogger(logger *Logger) CustomExporterOption {
 	}
 }
 
+// WithLogWriter 设置自定义的LogWriter
+func WithLogWriter(w LogWriter) CustomExporterOption {
+	return func(e *CustomExporter) {
+		e.logWriter = w
+	}
+}
+
+// WithBufferSize 设置缓冲的span数量，缓冲满时批量写入，未满的部分在ForceFlush或Shutdown时写入
+func WithBufferSize(size int) CustomExporterOption {
+	return func(e *CustomExporter) {
+		e.bufferSize = size
+	}
+}
+
+// spanLogFormat span日志格式，参数顺序与LogWriter实现中的下标对应
+const spanLogFormat = "name=%s trace_id=%s span_id=%s p_trace_id=%s p_span_id=%s event_names=%v resources=%v tracer_name=%s"
+
 // ExportSpans 实现trace.SpanExporter接口
+// Shutdown之后导出的span会被丢弃
 func (e *CustomExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	records := make([][]interface{}, 0, len(spans))
 	for _, span := range spans {
 		// 获取Name
 		name := span.Name()
@@ -163,18 +188,55 @@ func (e *CustomExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadO
 		// 获取Tracer名称（Instrumentation Scope）
 		tracerName := span.InstrumentationScope().Name
 
-		// 使用LogWriter写入日志
-		e.logWriter.WriteLog(
-			"name=%s trace_id=%s span_id=%s p_trace_id=%s p_span_id=%s event_names=%v resources=%v tracer_name=%s",
+		records = append(records, []interface{}{
 			name, traceID, spanID, parentTraceID, parentSpanID, eventNames, resources, tracerName,
-		)
+		})
+	}
+
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return nil
+	}
+	e.buffer = append(e.buffer, records...)
+	var pending [][]interface{}
+	if len(e.buffer) >= e.bufferSize {
+		pending, e.buffer = e.buffer, nil
+	}
+	e.mu.Unlock()
+
+	e.write(pending)
+	return nil
+}
+
+// write 使用LogWriter写入日志
+func (e *CustomExporter) write(records [][]interface{}) {
+	for _, args := range records {
+		e.logWriter.WriteLog(spanLogFormat, args...)
 	}
+}
 
+// ForceFlush 写入缓冲中的所有span
+func (e *CustomExporter) ForceFlush(ctx context.Context) error {
+	e.mu.Lock()
+	pending := e.buffer
+	e.buffer = nil
+	e.mu.Unlock()
+
+	e.write(pending)
 	return nil
 }
 
-// Shutdown 实现trace.SpanExporter接口
+// Shutdown 实现trace.SpanExporter接口，写入缓冲中剩余的span后停止导出
+// TracerProvider.Shutdown会先将批处理队列中的span交给导出器，再调用该方法
 func (e *CustomExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	pending := e.buffer
+	e.buffer = nil
+	e.stopped = true
+	e.mu.Unlock()
+
+	e.write(pending)
 	return nil
 }
 
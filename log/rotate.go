@@ -0,0 +1,203 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotateWriter 支持按大小轮转、按日期分文件及备份清理的日志文件writer
+//
+// 当前写入的文件名为Filename（ByDate时为Filename加日期，如app.20240102.log）；
+// 文件超过MaxSize后重命名为带序号的备份（如app.20240102.1.log），再重新创建当前文件。
+// ByDate时跨天会切换到新日期的文件，前一天的文件与按大小轮转产生的文件同样视为备份，
+// 按MaxBackups（保留个数，0表示不限制）及MaxAge（保留天数）清理。
+type rotateWriter struct {
+	mu         sync.Mutex
+	dir        string
+	filename   string // 配置的文件名，不含日期
+	byDate     bool
+	maxSize    int64 // 字节
+	maxAge     time.Duration
+	maxBackups int
+
+	file *os.File
+	name string // 当前文件名
+	size int64
+	date string // 当前文件的日期，ByDate时使用
+
+	now func() time.Time
+}
+
+// newRotateWriter 创建日志writer并打开当前文件
+// maxSize单位为MB，maxAge单位为天，<=0表示不限制
+func newRotateWriter(dir, filename string, byDate bool, maxSize, maxAge, maxBackups int) (*rotateWriter, error) {
+	w := &rotateWriter{
+		dir:        dir,
+		filename:   filename,
+		byDate:     byDate,
+		maxSize:    int64(maxSize) << 20,
+		maxAge:     time.Duration(maxAge) * 24 * time.Hour,
+		maxBackups: maxBackups,
+		now:        time.Now,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	w.cleanup()
+	return w, nil
+}
+
+// Write 写入日志，跨天或超过大小时先切换文件
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.byDate && w.now().Format(dateLayout) != w.date {
+		w.file.Close()
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+		w.cleanup()
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync 将文件内容刷入磁盘
+func (w *rotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Sync()
+}
+
+// Close 关闭当前文件
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// open 按当前日期打开（追加）日志文件
+func (w *rotateWriter) open() error {
+	w.date = w.now().Format(dateLayout)
+	w.name = w.filename
+	if w.byDate {
+		w.name = datedFilename(w.filename, w.date)
+	}
+	file, err := os.OpenFile(filepath.Join(w.dir, w.name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为下一个序号的备份，并重新创建当前文件
+func (w *rotateWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	base, ext := splitLogExt(w.name)
+	backup := fmt.Sprintf("%s.%d%s", base, w.nextIndex(base, ext), ext)
+	if err := os.Rename(filepath.Join(w.dir, w.name), filepath.Join(w.dir, backup)); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.cleanup()
+	return nil
+}
+
+// nextIndex 返回当前文件下一个可用的备份序号
+// 中间部分为日期的文件（如关闭ByDate前遗留的app.20240102.log）不是按大小轮转的备份，不参与计算
+func (w *rotateWriter) nextIndex(base, ext string) int {
+	entries, _ := os.ReadDir(w.dir)
+	next := 1
+	for _, entry := range entries {
+		middle, ok := backupMiddle(entry.Name(), base, ext)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, middle); err == nil {
+			continue
+		}
+		if n, err := strconv.Atoi(middle); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return next
+}
+
+// cleanup 按MaxBackups和MaxAge删除多余的备份，当前文件不会被删除
+// 清理失败不影响日志写入，因此忽略错误
+func (w *rotateWriter) cleanup() {
+	if w.maxBackups <= 0 && w.maxAge <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return
+	}
+	base, ext := splitLogExt(w.filename)
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.Name() == w.name || entry.IsDir() {
+			continue
+		}
+		if _, ok := backupMiddle(entry.Name(), base, ext); !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: entry.Name(), modTime: info.ModTime()})
+	}
+	// 新的在前
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+
+	cutoff := w.now().Add(-w.maxAge)
+	for i, b := range backups {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && b.modTime.Before(cutoff)) {
+			os.Remove(filepath.Join(w.dir, b.name))
+		}
+	}
+}
+
+// backupMiddle 判断name是否为base.<中间部分>ext形式的备份文件，中间部分只能由数字和点组成（日期、序号）
+func backupMiddle(name, base, ext string) (string, bool) {
+	if !strings.HasPrefix(name, base+".") || !strings.HasSuffix(name, ext) || len(name) <= len(base)+1+len(ext) {
+		return "", false
+	}
+	middle := name[len(base)+1 : len(name)-len(ext)]
+	for _, r := range middle {
+		if (r < '0' || r > '9') && r != '.' {
+			return "", false
+		}
+	}
+	return middle, true
+}
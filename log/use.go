@@ -2,6 +2,12 @@ package log
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	gorm_logger "gorm.io/gorm/logger"
@@ -40,8 +46,11 @@ func (l *Logger) Fatal(msg string, keysAndValues ...interface{}) {
 
 // GORMLogger GORM 日志接口实现
 type GORMLogger struct {
-	Logger *Logger
-	level  gorm_logger.LogLevel
+	Logger      *Logger
+	level       gorm_logger.LogLevel
+	withCaller  bool          // 是否记录发起查询的代码位置
+	contextKeys []interface{} // 需要从 context 中提取并记录的键
+	callerSkip  []string      // 查找调用位置时额外跳过的函数名前缀
 }
 
 // AsGORMLogger 将普通日志记录器转换为 GORM 日志记录器
@@ -64,6 +73,33 @@ func (g *GORMLogger) LogMode(level gorm_logger.LogLevel) gorm_logger.Interface {
 	return &newLogger
 }
 
+// WithCaller 返回记录调用位置的新日志记录器，SQL 日志会带上发起查询的 file:line（sql_caller 字段）
+func (g *GORMLogger) WithCaller() *GORMLogger {
+	newLogger := *g
+	newLogger.withCaller = true
+	return &newLogger
+}
+
+// WithCallerSkip 返回查找调用位置时额外跳过指定包的新日志记录器
+// 业务代码自行封装了数据访问层时，传入封装所在的包路径（如 "example.com/app/dao"），
+// sql_caller 即为调用封装的业务代码位置；gorm 内部、本包及 database 包默认跳过
+func (g *GORMLogger) WithCallerSkip(pkgPaths ...string) *GORMLogger {
+	newLogger := *g
+	newLogger.callerSkip = append([]string{}, g.callerSkip...)
+	for _, pkgPath := range pkgPaths {
+		newLogger.callerSkip = append(newLogger.callerSkip, pkgPath+".")
+	}
+	return &newLogger
+}
+
+// WithFields 返回从 context 中提取指定键的新日志记录器，如请求ID、用户ID
+// 键存在时以 fmt.Sprint(key) 为字段名记录，不存在时忽略
+func (g *GORMLogger) WithFields(keys ...interface{}) *GORMLogger {
+	newLogger := *g
+	newLogger.contextKeys = append(append([]interface{}{}, g.contextKeys...), keys...)
+	return &newLogger
+}
+
 // Info 记录信息级别日志
 func (g *GORMLogger) Info(ctx context.Context, msg string, data ...interface{}) {
 	if g.level >= gorm_logger.Info {
@@ -100,6 +136,14 @@ func (g *GORMLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 		"rows", rows,
 		"sql", sql,
 	}
+	if g.withCaller {
+		fields = append(fields, "sql_caller", sqlCaller(g.callerSkip))
+	}
+	for _, key := range g.contextKeys {
+		if value := ctx.Value(key); value != nil {
+			fields = append(fields, fmt.Sprint(key), value)
+		}
+	}
 
 	switch {
 	case err != nil && g.level >= gorm_logger.Error:
@@ -111,4 +155,36 @@ func (g *GORMLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 	}
 }
 
+// callerSkipPrefixes 查找调用位置时默认跳过的函数名前缀：gorm 内部、本包及封装了 gorm 的 database 包
+var callerSkipPrefixes = func() []string {
+	logPkg := reflect.TypeOf(GORMLogger{}).PkgPath()
+	return []string{"gorm.io/", logPkg + ".", path.Dir(logPkg) + "/database."}
+}()
+
+// sqlCaller 返回发起查询的代码位置，跳过 callerSkipPrefixes 及 extra 中的调用栈
+func sqlCaller(extra []string) string {
+	pcs := [64]uintptr{}
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !hasAnyPrefix(frame.Function, callerSkipPrefixes) && !hasAnyPrefix(frame.Function, extra) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// hasAnyPrefix 判断s是否以prefixes中的任一前缀开头
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // gorm 日志接口实现 end
@@ -0,0 +1,111 @@
+package log_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/database"
+	"github.com/lwy110193/go_vendor/log"
+	"github.com/lwy110193/go_vendor/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type ctxKey string
+
+// TestGORMLoggerCallerAndFields 测试SQL日志包含调用位置和context字段
+func TestGORMLoggerCallerAndFields(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := log.New(log.Config{
+		FileOutEnable: true,
+		Level:         log.DEBUG,
+		OutputDir:     dir,
+		Filename:      "gorm.log",
+		FlushOnWrite:  true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+
+	gormLogger := logger.AsGORMLogger().WithCaller().WithFields(ctxKey("request_id"), ctxKey("user_id"))
+	ctx := context.WithValue(context.Background(), ctxKey("request_id"), "req-1")
+	gormLogger.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+	data, err := os.ReadFile(filepath.Join(dir, "gorm.log"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal(data, &line); err != nil {
+		t.Fatalf("Unmarshal() error = %v, data = %s", err, data)
+	}
+
+	caller, _ := line["sql_caller"].(string)
+	if !strings.Contains(caller, "use_test.go:") {
+		t.Errorf("sql_caller = %q, want callsite in use_test.go", caller)
+	}
+	if line["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", line["request_id"])
+	}
+	if _, ok := line["user_id"]; ok {
+		t.Errorf("user_id should be omitted when absent from context")
+	}
+}
+
+// callerItem BaseRepo调用位置测试表
+type callerItem struct {
+	ID   uint64 `gorm:"primaryKey;column:id"`
+	Name string `gorm:"column:name"`
+}
+
+func (c *callerItem) TableName() string {
+	return "caller_item"
+}
+
+// TestGORMLoggerCallerThroughBaseRepo 测试经由database.BaseRepo发起的查询，sql_caller为调用BaseRepo的业务代码位置
+func TestGORMLoggerCallerThroughBaseRepo(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := log.New(log.Config{
+		FileOutEnable: true,
+		Level:         log.DEBUG,
+		OutputDir:     dir,
+		Filename:      "gorm.log",
+		FlushOnWrite:  true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.AutoMigrate(&callerItem{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	db.Logger = logger.AsGORMLogger().WithCaller()
+
+	repo := database.BaseRepo{Db: db, Model: &callerItem{}}
+	var items []*callerItem
+	if _, err := repo.Find(context.Background(), &items, utils.MI{"name": "a"}, nil); err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	lines := readLogLines(t, filepath.Join(dir, "gorm.log"))
+	if len(lines) == 0 {
+		t.Fatalf("no SQL logged")
+	}
+	for _, line := range lines {
+		caller, _ := line["sql_caller"].(string)
+		if !strings.Contains(caller, "use_test.go:") {
+			t.Errorf("sql_caller = %q, want callsite in use_test.go", caller)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package perfomance
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// DefaultLatencyBuckets 默认的请求耗时分桶(秒)，覆盖5ms到10s
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NewLatencyHistogramView 创建指定直方图的分桶视图
+// 视图需要在创建 MeterProvider 时注册，例如：
+//
+//	view := perfomance.NewLatencyHistogramView("http_request_duration_seconds", nil)
+//	perfomance.InitOpenTelemetryPrometheus("app", view)
+//
+// buckets 为空时使用 DefaultLatencyBuckets
+func NewLatencyHistogramView(name string, buckets []float64) sdkmetric.View {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	return sdkmetric.NewView(
+		sdkmetric.Instrument{Name: name, Kind: sdkmetric.InstrumentKindHistogram},
+		sdkmetric.Stream{
+			Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: buckets},
+		},
+	)
+}
+
+// NewLatencyHistogram 创建请求耗时直方图(单位秒)
+// buckets 同时作为建议分桶传给SDK，未注册视图时也会生效；注册了视图时以视图为准
+// 需先调用 InitOpenTelemetryPrometheus 初始化全局 Meter
+func NewLatencyHistogram(name string, buckets []float64) (metric.Float64Histogram, error) {
+	if meter == nil {
+		return nil, errors.New("meter is not initialized, call InitOpenTelemetryPrometheus first")
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	histogram, err := meter.Float64Histogram(
+		name,
+		metric.WithDescription("Request duration in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(buckets...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create histogram: %w", err)
+	}
+	return histogram, nil
+}
@@ -0,0 +1,75 @@
+package perfomance
+
+import (
+	"context"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// TestLatencyHistogramView 测试自定义分桶视图生效
+func TestLatencyHistogramView(t *testing.T) {
+	buckets := []float64{0.005, 0.05, 0.5, 5, 10}
+	reader := sdkmetric.NewManualReader()
+	meterProvider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(NewLatencyHistogramView("http_request_duration_seconds", buckets)),
+	)
+	meter = meterProvider.Meter("test")
+	defer func() {
+		meterProvider.Shutdown(context.Background())
+		meterProvider, meter = nil, nil
+	}()
+
+	histogram, err := NewLatencyHistogram("http_request_duration_seconds", nil)
+	if err != nil {
+		t.Fatalf("NewLatencyHistogram failed: %v", err)
+	}
+	ctx := context.Background()
+	histogram.Record(ctx, 0.003)
+	histogram.Record(ctx, 0.2)
+	histogram.Record(ctx, 7)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("Expected 1 metric, got %+v", rm.ScopeMetrics)
+	}
+	data, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	if !ok || len(data.DataPoints) != 1 {
+		t.Fatalf("Unexpected metric data: %+v", rm.ScopeMetrics[0].Metrics[0].Data)
+	}
+
+	point := data.DataPoints[0]
+	if point.Count != 3 {
+		t.Errorf("Expected count 3, got %d", point.Count)
+	}
+	// 视图中的分桶优先于建议分桶
+	if len(point.Bounds) != len(buckets) {
+		t.Fatalf("Expected bounds %v, got %v", buckets, point.Bounds)
+	}
+	for i := range buckets {
+		if point.Bounds[i] != buckets[i] {
+			t.Errorf("Expected bounds %v, got %v", buckets, point.Bounds)
+			break
+		}
+	}
+	// 0.003 -> (0,0.005], 0.2 -> (0.05,0.5], 7 -> (5,10]
+	expected := []uint64{1, 0, 1, 0, 1, 0}
+	for i, c := range expected {
+		if point.BucketCounts[i] != c {
+			t.Errorf("Expected bucket counts %v, got %v", expected, point.BucketCounts)
+			break
+		}
+	}
+}
+
+// TestNewLatencyHistogramNotInitialized 测试未初始化时返回错误
+func TestNewLatencyHistogramNotInitialized(t *testing.T) {
+	if _, err := NewLatencyHistogram("latency", nil); err == nil {
+		t.Error("Expected error when meter is not initialized")
+	}
+}
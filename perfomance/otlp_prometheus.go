@@ -22,7 +22,8 @@ var (
 )
 
 // InitOpenTelemetryPrometheus 初始化 OpenTelemetry Prometheus 导出器
-func InitOpenTelemetryPrometheus(name string) error {
+// views: 可选的指标视图，用于自定义直方图分桶等，如 NewLatencyHistogramView
+func InitOpenTelemetryPrometheus(name string, views ...sdkmetric.View) error {
 	// 创建 Prometheus 导出器
 	exporter, err := prometheus.New()
 	if err != nil {
@@ -32,6 +33,7 @@ func InitOpenTelemetryPrometheus(name string) error {
 	// 创建 MeterProvider
 	meterProvider = sdkmetric.NewMeterProvider(
 		sdkmetric.WithReader(exporter),
+		sdkmetric.WithView(views...),
 	)
 
 	// 设置全局 MeterProvider
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// 重试退避策略
+const (
+	RetryBackoffFixed       = "fixed"       // 固定间隔：RetryDelay
+	RetryBackoffLinear      = "linear"      // 线性增长：RetryDelay * n
+	RetryBackoffExponential = "exponential" // 指数增长：RetryDelay * 2^(n-1)
+)
+
+// retryDelay 计算第n次重试（从1开始）前的等待时间
+// 设置了MaxRetryDelay时不超过该值；开启RetryJitter时在[delay/2, delay]之间随机，避免多个客户端同时重试
+func (c *Client) retryDelay(n int) time.Duration {
+	base := c.config.RetryDelay
+	if base <= 0 || n < 1 {
+		return 0
+	}
+
+	delay := base
+	switch c.config.RetryBackoff {
+	case RetryBackoffLinear:
+		delay = base * time.Duration(n)
+	case RetryBackoffExponential:
+		// 避免移位溢出，超过62位时直接视为无穷大，由MaxRetryDelay截断
+		if shift := n - 1; shift < 62 && base <= time.Duration(1<<62)>>shift {
+			delay = base << shift
+		} else {
+			delay = time.Duration(1<<63 - 1)
+		}
+	}
+
+	if c.config.MaxRetryDelay > 0 && delay > c.config.MaxRetryDelay {
+		delay = c.config.MaxRetryDelay
+	}
+	if c.config.RetryJitter && delay > 1 {
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(delay-half)+1))
+	}
+	return delay
+}
+
+// waitRetry 第n次重试前等待，ctx结束时提前返回其错误
+func (c *Client) waitRetry(ctx context.Context, n int) error {
+	return c.sleep(ctx, c.retryDelay(n))
+}
+
+// sleepContext 等待d，ctx结束时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
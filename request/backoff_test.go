@@ -0,0 +1,87 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// recordSleeps 替换客户端的重试等待函数，记录每次等待时间且不真正等待
+func recordSleeps(c *Client) *[]time.Duration {
+	var sleeps []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return &sleeps
+}
+
+// TestRetryBackoff 测试各退避策略的等待时间增长规律
+func TestRetryBackoff(t *testing.T) {
+	base := 100 * time.Millisecond
+	tests := []struct {
+		backoff string
+		max     time.Duration
+		want    []time.Duration
+	}{
+		{"", 0, []time.Duration{base, base, base, base}},
+		{RetryBackoffFixed, 0, []time.Duration{base, base, base, base}},
+		{RetryBackoffLinear, 0, []time.Duration{base, 2 * base, 3 * base, 4 * base}},
+		{RetryBackoffExponential, 0, []time.Duration{base, 2 * base, 4 * base, 8 * base}},
+		{RetryBackoffExponential, 300 * time.Millisecond, []time.Duration{base, 2 * base, 300 * time.Millisecond, 300 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		rt := &mockTransport{statuses: []int{http.StatusServiceUnavailable}, body: `{}`}
+		client := NewClientWithTransport(&Config{
+			Timeout:       5 * time.Second,
+			RetryCount:    4,
+			RetryDelay:    base,
+			RetryBackoff:  tt.backoff,
+			MaxRetryDelay: tt.max,
+		}, rt)
+		sleeps := recordSleeps(client)
+
+		if _, err := client.Get("http://example.com", nil, nil); err != nil {
+			t.Fatalf("[%s] Get failed: %v", tt.backoff, err)
+		}
+		if len(*sleeps) != len(tt.want) {
+			t.Fatalf("[%s] Expected %d sleeps, got %v", tt.backoff, len(tt.want), *sleeps)
+		}
+		for i, want := range tt.want {
+			if (*sleeps)[i] != want {
+				t.Errorf("[%s] sleep %d = %v, want %v", tt.backoff, i+1, (*sleeps)[i], want)
+			}
+		}
+	}
+}
+
+// TestRetryBackoffJitter 测试抖动后的等待时间在[delay/2, delay]之间
+func TestRetryBackoffJitter(t *testing.T) {
+	base := 100 * time.Millisecond
+	client := NewClientWithTransport(&Config{
+		RetryDelay:    base,
+		RetryBackoff:  RetryBackoffExponential,
+		MaxRetryDelay: time.Second,
+		RetryJitter:   true,
+	}, nil)
+
+	for n := 1; n <= 6; n++ {
+		full := base << (n - 1)
+		if full > time.Second {
+			full = time.Second
+		}
+		for i := 0; i < 50; i++ {
+			if d := client.retryDelay(n); d < full/2 || d > full {
+				t.Fatalf("retryDelay(%d) = %v, want in [%v, %v]", n, d, full/2, full)
+			}
+		}
+	}
+
+	// 指数增长不会溢出
+	client.config.RetryJitter = false
+	if d := client.retryDelay(100); d != time.Second {
+		t.Errorf("retryDelay(100) = %v, want capped at 1s", d)
+	}
+}
@@ -0,0 +1,233 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lwy110193/go_vendor/event"
+)
+
+// ErrCircuitOpen 熔断器打开时请求直接失败返回的错误
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	// CircuitClosed 关闭状态，请求正常发送
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 打开状态，请求直接返回ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen 半开状态，冷却结束后只放行一个探测请求
+	CircuitHalfOpen
+)
+
+// String 返回熔断器状态的字符串表示
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitStateChange 熔断器状态变化事件
+type CircuitStateChange struct {
+	Host string // 发生变化的主机，与请求URL的Host一致，如"api.example.com:8443"
+	From CircuitState
+	To   CircuitState
+}
+
+// CircuitBreakerConfig 熔断器配置
+// 按请求的主机分别统计和熔断，网络错误与5xx响应计为失败，其他响应计为成功；ctx取消或超时引起的错误不计入
+type CircuitBreakerConfig struct {
+	FailureThreshold int                            `yaml:"failure_threshold"` // 连续失败次数达到该值时打开熔断，默认5
+	Window           time.Duration                  `yaml:"window"`            // 连续失败的统计窗口，距上次失败超过该时长时重新计数，0表示不限制
+	Cooldown         time.Duration                  `yaml:"cooldown"`          // 打开后的冷却时间，到期后进入半开状态放行一个探测请求，默认30秒
+	Events           *event.Bus[CircuitStateChange] `yaml:"-"`                 // 状态变化事件总线，可选，用于告警或监控
+}
+
+// circuitBreakers 按主机划分的熔断器，一个主机熔断不影响其他主机（如FallbackBaseURLs中的备用主机）
+type circuitBreakers struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*circuitBreaker
+	now    func() time.Time
+}
+
+// newCircuitBreakers 创建熔断器，config为nil时返回nil表示不启用
+func newCircuitBreakers(config *CircuitBreakerConfig) *circuitBreakers {
+	if config == nil {
+		return nil
+	}
+	cfg := *config
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &circuitBreakers{config: cfg, hosts: make(map[string]*circuitBreaker), now: time.Now}
+}
+
+// get 返回host对应的熔断器，不存在时创建
+func (g *circuitBreakers) get(host string) *circuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.hosts[host]
+	if !ok {
+		b = &circuitBreaker{config: g.config, host: host, now: g.now}
+		g.hosts[host] = b
+	}
+	return b
+}
+
+// circuitBreaker 单个主机基于连续失败次数的熔断器
+// 每次实际发送的请求（包括重试）都经过熔断器，熔断打开后重试循环也会立即停止
+type circuitBreaker struct {
+	config      CircuitBreakerConfig
+	host        string
+	mu          sync.Mutex
+	state       CircuitState
+	failures    int
+	lastFailure time.Time
+	openedAt    time.Time
+	probing     bool                 // 半开状态下是否已有探测请求在进行
+	pending     []CircuitStateChange // 待发布的状态变化事件，在锁外发布
+	now         func() time.Time
+}
+
+// allow 判断是否放行请求，不放行时返回ErrCircuitOpen
+func (b *circuitBreaker) allow() error {
+	defer b.publishPending()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.config.Cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(CircuitHalfOpen)
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record 记录一次请求的结果
+func (b *circuitBreaker) record(resp *http.Response, err error) {
+	defer b.publishPending()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// ctx取消或超时与上游状态无关，只释放探测名额
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		b.probing = false
+		return
+	}
+
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		b.failures = 0
+		b.probing = false
+		if b.state != CircuitClosed {
+			b.setState(CircuitClosed)
+		}
+		return
+	}
+
+	now := b.now()
+	if b.state == CircuitHalfOpen {
+		// 探测失败，重新打开
+		b.probing = false
+		b.openedAt = now
+		b.setState(CircuitOpen)
+		return
+	}
+	if b.config.Window > 0 && now.Sub(b.lastFailure) > b.config.Window {
+		b.failures = 0
+	}
+	b.failures++
+	b.lastFailure = now
+	if b.state == CircuitClosed && b.failures >= b.config.FailureThreshold {
+		b.openedAt = now
+		b.setState(CircuitOpen)
+	}
+}
+
+// State 返回当前状态，打开状态冷却到期但尚未有请求时仍返回CircuitOpen
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// setState 切换状态并记录待发布的事件，调用方需持有锁
+func (b *circuitBreaker) setState(state CircuitState) {
+	if b.config.Events != nil {
+		b.pending = append(b.pending, CircuitStateChange{Host: b.host, From: b.state, To: state})
+	}
+	b.state = state
+	if state == CircuitClosed {
+		b.failures = 0
+	}
+}
+
+// publishPending 在锁外发布状态变化事件，避免订阅者回调中使用客户端时死锁
+func (b *circuitBreaker) publishPending() {
+	if b.config.Events == nil {
+		return
+	}
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	for _, change := range pending {
+		b.config.Events.Publish(change)
+	}
+}
+
+// allowRequest 判断请求主机的熔断器是否放行，未启用熔断器时总是放行
+func (c *Client) allowRequest(req *http.Request) error {
+	if c.breakers == nil {
+		return nil
+	}
+	return c.breakers.get(req.URL.Host).allow()
+}
+
+// recordResult 向请求主机的熔断器记录一次请求的结果
+func (c *Client) recordResult(req *http.Request, resp *http.Response, err error) {
+	if c.breakers != nil {
+		c.breakers.get(req.URL.Host).record(resp, err)
+	}
+}
+
+// CircuitState 返回host（与请求URL的Host一致，如"api.example.com:8443"）的熔断器状态
+// 未启用熔断器或该主机尚未发送过请求时返回CircuitClosed
+func (c *Client) CircuitState(host string) CircuitState {
+	if c.breakers == nil {
+		return CircuitClosed
+	}
+	c.breakers.mu.Lock()
+	b, ok := c.breakers.hosts[host]
+	c.breakers.mu.Unlock()
+	if !ok {
+		return CircuitClosed
+	}
+	return b.State()
+}
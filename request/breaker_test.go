@@ -0,0 +1,137 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/event"
+)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// newBreakerClient 创建启用熔断器的客户端，熔断器使用手动时钟
+func newBreakerClient(rt http.RoundTripper, config CircuitBreakerConfig, retryCount int) (*Client, *fakeClock) {
+	client := NewClientWithTransport(&Config{
+		Timeout:        5 * time.Second,
+		RetryCount:     retryCount,
+		CircuitBreaker: &config,
+	}, rt)
+	recordSleeps(client)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	client.breakers.now = clock.Now
+	return client, clock
+}
+
+// TestCircuitBreakerTripAndRecover 测试连续失败后熔断、快速失败及冷却后恢复
+func TestCircuitBreakerTripAndRecover(t *testing.T) {
+	events := event.NewBus[CircuitStateChange]()
+	var changes []CircuitStateChange
+	events.Subscribe(func(c CircuitStateChange) { changes = append(changes, c) })
+
+	rt := &mockTransport{statuses: []int{500, 502, 503, 200}, body: `{}`}
+	client, clock := newBreakerClient(rt, CircuitBreakerConfig{FailureThreshold: 3, Cooldown: 10 * time.Second, Events: events}, 0)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get("http://mock.local/down", nil, nil); err != nil {
+			t.Fatalf("Get %d failed: %v", i, err)
+		}
+	}
+	if client.CircuitState("mock.local") != CircuitOpen {
+		t.Fatalf("Expected circuit open, got %s", client.CircuitState("mock.local"))
+	}
+
+	// 冷却期内快速失败，不发送请求
+	if _, err := client.Get("http://mock.local/down", nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if rt.calls != 3 {
+		t.Errorf("Expected 3 upstream calls, got %d", rt.calls)
+	}
+
+	// 冷却结束后放行探测请求，成功后关闭
+	clock.now = clock.now.Add(10 * time.Second)
+	resp, err := client.Get("http://mock.local/down", nil, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected probe to succeed, got %v, %v", resp, err)
+	}
+	if client.CircuitState("mock.local") != CircuitClosed {
+		t.Errorf("Expected circuit closed, got %s", client.CircuitState("mock.local"))
+	}
+
+	want := []CircuitStateChange{
+		{"mock.local", CircuitClosed, CircuitOpen},
+		{"mock.local", CircuitOpen, CircuitHalfOpen},
+		{"mock.local", CircuitHalfOpen, CircuitClosed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected state changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %v, want %v", i, changes[i], want[i])
+		}
+	}
+}
+
+// TestCircuitBreakerHalfOpenProbe 测试半开状态只放行一个探测请求，探测失败重新打开
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	rt := &mockTransport{statuses: []int{500}, body: `{}`}
+	client, clock := newBreakerClient(rt, CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Second}, 0)
+
+	client.Get("http://mock.local/down", nil, nil)
+	clock.now = clock.now.Add(time.Second)
+
+	b := client.breakers.get("mock.local")
+	if err := b.allow(); err != nil {
+		t.Fatalf("Expected probe to be allowed, got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected concurrent request to fail fast, got %v", err)
+	}
+	b.record(&http.Response{StatusCode: http.StatusInternalServerError}, nil)
+	if b.State() != CircuitOpen {
+		t.Errorf("Expected failed probe to reopen circuit, got %s", b.State())
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected cooldown to restart after failed probe, got %v", err)
+	}
+}
+
+// TestCircuitBreakerStopsRetries 测试熔断打开后重试循环立即停止
+func TestCircuitBreakerStopsRetries(t *testing.T) {
+	rt := &mockTransport{statuses: []int{503}, body: `{}`}
+	client, _ := newBreakerClient(rt, CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}, 5)
+
+	resp, err := client.Get("http://mock.local/down", nil, nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected last 503 response, got %+v", resp)
+	}
+	if rt.calls != 2 {
+		t.Errorf("Expected 2 upstream calls, got %d", rt.calls)
+	}
+}
+
+// TestCircuitBreakerWindow 测试超出统计窗口的失败不累计，4xx不计为失败
+func TestCircuitBreakerWindow(t *testing.T) {
+	rt := &mockTransport{statuses: []int{500, 404, 500, 500}, body: `{}`}
+	client, clock := newBreakerClient(rt, CircuitBreakerConfig{FailureThreshold: 2, Window: time.Second}, 0)
+
+	client.Get("http://mock.local/a", nil, nil) // 500
+	client.Get("http://mock.local/a", nil, nil) // 404，重置计数
+	client.Get("http://mock.local/a", nil, nil) // 500
+	clock.now = clock.now.Add(2 * time.Second)
+	client.Get("http://mock.local/a", nil, nil) // 500，距上次失败超过窗口，重新计数
+	if client.CircuitState("mock.local") != CircuitClosed {
+		t.Errorf("Expected circuit closed, got %s", client.CircuitState("mock.local"))
+	}
+}
@@ -0,0 +1,50 @@
+package request
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clone 复制客户端，用于按作用域（如每个租户）定制请求头、超时等配置
+// 副本与原客户端共享Transport（及其连接池）、代理池和熔断器，配置则各自独立：
+// 修改副本的Headers等配置不会影响原客户端，反之亦然
+func (c *Client) Clone() *Client {
+	config := *c.config
+	config.Headers = maps.Clone(c.config.Headers)
+	config.ProxyURLs = slices.Clone(c.config.ProxyURLs)
+	config.ProxyWeights = slices.Clone(c.config.ProxyWeights)
+	config.FallbackBaseURLs = slices.Clone(c.config.FallbackBaseURLs)
+	config.Interceptors = slices.Clone(c.config.Interceptors)
+
+	httpClient := *c.httpClient
+	return &Client{
+		config:        &config,
+		httpClient:    &httpClient,
+		log:           c.log,
+		proxyURLs:     c.proxyURLs,
+		proxyStrategy: c.proxyStrategy,
+		proxyWeights:  c.proxyWeights,
+		random:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		mu:            sync.Mutex{},
+		sleep:         c.sleep,
+		breakers:      c.breakers,
+	}
+}
+
+// WithHeader 返回设置了全局请求头key的副本，原客户端不受影响
+func (c *Client) WithHeader(key, value string) *Client {
+	clone := c.Clone()
+	clone.config.Headers[key] = value
+	return clone
+}
+
+// WithTimeout 返回超时时间为timeout的副本，原客户端不受影响
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	clone := c.Clone()
+	clone.config.Timeout = timeout
+	clone.httpClient.Timeout = timeout
+	return clone
+}
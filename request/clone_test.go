@@ -0,0 +1,53 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestClone 测试副本共享Transport，请求头与超时相互独立
+func TestClone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Tenant") + "|" + r.Header.Get("X-Base")))
+	}))
+	defer server.Close()
+
+	base := NewClient(&Config{Timeout: 5 * time.Second, Headers: map[string]string{"X-Base": "base"}}, nil)
+	tenantA := base.WithHeader("X-Tenant", "a")
+	tenantB := base.WithHeader("X-Tenant", "b").WithTimeout(2 * time.Second)
+
+	if tenantA.httpClient.Transport != base.httpClient.Transport || tenantB.httpClient.Transport != base.httpClient.Transport {
+		t.Error("Expected clones to share the parent's transport")
+	}
+	if tenantB.httpClient.Timeout != 2*time.Second || tenantB.config.Timeout != 2*time.Second {
+		t.Errorf("Expected clone timeout 2s, got %v", tenantB.httpClient.Timeout)
+	}
+	if base.httpClient.Timeout != 5*time.Second || tenantA.config.Timeout != 5*time.Second {
+		t.Errorf("Parent and sibling timeouts should be unchanged, got %v and %v", base.httpClient.Timeout, tenantA.config.Timeout)
+	}
+
+	for _, tc := range []struct {
+		client *Client
+		want   string
+	}{
+		{base, "|base"},
+		{tenantA, "a|base"},
+		{tenantB, "b|base"},
+	} {
+		resp, err := tc.client.Get(server.URL, nil, nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if string(resp.Body) != tc.want {
+			t.Errorf("Expected %q, got %q", tc.want, resp.Body)
+		}
+	}
+
+	// 修改副本的配置不影响原客户端
+	tenantA.config.Headers["X-Base"] = "changed"
+	if base.config.Headers["X-Base"] != "base" {
+		t.Errorf("Parent headers modified by clone: %v", base.config.Headers)
+	}
+}
@@ -0,0 +1,37 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+)
+
+// defaultCompressMinSize 默认请求体压缩阈值，过小的请求体压缩后收益有限甚至变大
+const defaultCompressMinSize = 1024
+
+// shouldCompress 判断请求体是否需要压缩
+func (c *Client) shouldCompress(body []byte, headers map[string]string) bool {
+	if !c.config.CompressRequestBody || len(body) < c.config.CompressMinSize {
+		return false
+	}
+	for key := range headers {
+		if http.CanonicalHeaderKey(key) == "Content-Encoding" {
+			return false
+		}
+	}
+	return true
+}
+
+// gzipBody 使用gzip压缩请求体
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
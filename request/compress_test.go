@@ -0,0 +1,66 @@
+package request
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCompressRequestBody 测试请求体gzip压缩，服务端解压后校验内容
+func TestCompressRequestBody(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Failed to create gzip reader: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer gr.Close()
+			reader = gr
+		}
+
+		var payload map[string]string
+		if err := json.NewDecoder(reader).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]int{"size": len(payload["data"])})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second, CompressRequestBody: true}, nil)
+
+	// 大请求体被压缩
+	var result map[string]int
+	large := strings.Repeat("a", 4096)
+	if err := client.PostJSON(server.URL, map[string]string{"data": large}, nil, &result); err != nil {
+		t.Fatalf("PostJSON failed: %v", err)
+	}
+	if result["size"] != len(large) {
+		t.Errorf("Expected server to receive %d bytes, got %d", len(large), result["size"])
+	}
+
+	// 小于阈值的请求体不压缩
+	if err := client.PostJSON(server.URL, map[string]string{"data": "small"}, nil, &result); err != nil {
+		t.Fatalf("PostJSON failed: %v", err)
+	}
+	if result["size"] != len("small") {
+		t.Errorf("Expected server to receive 5 bytes, got %d", result["size"])
+	}
+
+	if len(encodings) != 2 || encodings[0] != "gzip" || encodings[1] != "" {
+		t.Errorf("Unexpected Content-Encoding headers: %q", encodings)
+	}
+}
@@ -0,0 +1,63 @@
+package request
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding 启用自动解压时默认发送的Accept-Encoding
+const acceptEncoding = "gzip, deflate, br"
+
+// decodeBody 根据Content-Encoding返回解压后的响应体读取器
+// 解压后移除Content-Encoding与Content-Length响应头，与标准库自动解压gzip的行为一致
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+
+	var reader io.Reader
+	switch encoding {
+	case "", "identity":
+		return resp.Body, nil
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		reader = gr
+	case "deflate":
+		// deflate标准格式为zlib封装，部分服务端会直接发送原始deflate数据
+		br := bufio.NewReader(resp.Body)
+		header, err := br.Peek(2)
+		if err == nil && isZlibHeader(header) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create zlib reader: %w", err)
+			}
+			reader = zr
+		} else {
+			reader = flate.NewReader(br)
+		}
+	case "br":
+		reader = brotli.NewReader(resp.Body)
+	default:
+		// 未知编码保持原样返回
+		return resp.Body, nil
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	return reader, nil
+}
+
+// isZlibHeader 判断是否为zlib格式的头部
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
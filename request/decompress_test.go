@@ -0,0 +1,130 @@
+package request
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressedServer 按encoding压缩响应体的测试服务器
+func compressedServer(t *testing.T, encoding string, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), encoding) {
+			t.Errorf("Expected Accept-Encoding to contain %s, got %s", encoding, r.Header.Get("Accept-Encoding"))
+		}
+
+		var buf bytes.Buffer
+		var wc io.WriteCloser
+		switch encoding {
+		case "gzip":
+			wc = gzip.NewWriter(&buf)
+		case "deflate":
+			wc = zlib.NewWriter(&buf)
+		case "br":
+			wc = brotli.NewWriter(&buf)
+		}
+		wc.Write([]byte(body))
+		wc.Close()
+
+		w.Header().Set("Content-Encoding", encoding)
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}))
+}
+
+// TestDecompression 测试gzip/deflate/br响应自动解压
+func TestDecompression(t *testing.T) {
+	body := strings.Repeat(`{"message":"compressed"}`, 100)
+	for _, encoding := range []string{"gzip", "deflate", "br"} {
+		server := compressedServer(t, encoding, body)
+		client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+
+		resp, err := client.Get(server.URL, nil, nil)
+		server.Close()
+		if err != nil {
+			t.Fatalf("[%s] Get failed: %v", encoding, err)
+		}
+		if string(resp.Body) != body {
+			t.Errorf("[%s] Unexpected decoded body: %q", encoding, resp.Body)
+		}
+		if resp.Headers.Get("Content-Encoding") != "" {
+			t.Errorf("[%s] Expected Content-Encoding to be removed", encoding)
+		}
+	}
+}
+
+// TestDecompressionCustomAcceptEncoding 测试调用方自行设置Accept-Encoding时仍自动解压，GetJSON可直接解析
+func TestDecompressionCustomAcceptEncoding(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate"} {
+		server := compressedServer(t, encoding, `{"message":"compressed","code":200}`)
+		client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+
+		var result MockResponse
+		err := client.GetJSON(server.URL, nil, map[string]string{"Accept-Encoding": encoding}, &result)
+		server.Close()
+		if err != nil {
+			t.Fatalf("[%s] GetJSON failed: %v", encoding, err)
+		}
+		if result.Message != "compressed" || result.Code != 200 {
+			t.Errorf("[%s] Unexpected result: %+v", encoding, result)
+		}
+	}
+}
+
+// TestRawDeflateDecompression 测试未使用zlib封装的deflate响应
+func TestRawDeflateDecompression(t *testing.T) {
+	body := "raw deflate body"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		fw.Write([]byte(body))
+		fw.Close()
+		w.Header().Set("Content-Encoding", "deflate")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(&Config{Timeout: 5 * time.Second}, nil).Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(resp.Body) != body {
+		t.Errorf("Unexpected decoded body: %q", resp.Body)
+	}
+}
+
+// TestDisableDecompression 测试禁用解压时返回原始响应体
+func TestDisableDecompression(t *testing.T) {
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		var buf bytes.Buffer
+		bw := brotli.NewWriter(&buf)
+		bw.Write([]byte("brotli body"))
+		bw.Close()
+		w.Header().Set("Content-Encoding", "br")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second, DisableDecompression: true}, nil)
+	resp, err := client.Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if strings.Contains(acceptEncoding, "br") {
+		t.Errorf("Expected br not to be advertised, got Accept-Encoding %q", acceptEncoding)
+	}
+	if resp.Headers.Get("Content-Encoding") != "br" || string(resp.Body) == "brotli body" {
+		t.Errorf("Expected raw brotli body, got %q", resp.Body)
+	}
+}
@@ -0,0 +1,52 @@
+package request
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// defaultClient 通过SetDefaultClient设置的默认客户端，为nil时使用builtinClient
+	defaultClient atomic.Pointer[Client]
+	// builtinClient 内置默认客户端，首次使用时创建：30秒超时，不重试
+	builtinClient     *Client
+	builtinClientOnce sync.Once
+)
+
+// DefaultClient 返回包级函数Get、Post、GetJSON、PostJSON使用的客户端
+func DefaultClient() *Client {
+	if c := defaultClient.Load(); c != nil {
+		return c
+	}
+	builtinClientOnce.Do(func() {
+		builtinClient = NewClient(&Config{Timeout: 30 * time.Second}, nil)
+	})
+	return builtinClient
+}
+
+// SetDefaultClient 替换包级函数使用的客户端，传nil恢复内置默认客户端
+// 可与包级函数并发调用
+func SetDefaultClient(c *Client) {
+	defaultClient.Store(c)
+}
+
+// Get 使用默认客户端执行GET请求，类似http.Get，适合脚本等简单场景
+func Get(url string, params map[string]string, headers map[string]string) (*Response, error) {
+	return DefaultClient().Get(url, params, headers)
+}
+
+// Post 使用默认客户端执行POST请求
+func Post(url string, body []byte, headers map[string]string) (*Response, error) {
+	return DefaultClient().Post(url, body, headers)
+}
+
+// GetJSON 使用默认客户端执行GET请求并解析JSON响应
+func GetJSON(url string, params map[string]string, headers map[string]string, result interface{}) error {
+	return DefaultClient().GetJSON(url, params, headers, result)
+}
+
+// PostJSON 使用默认客户端执行POST请求，请求体序列化为JSON并解析JSON响应
+func PostJSON(url string, data interface{}, headers map[string]string, result interface{}) error {
+	return DefaultClient().PostJSON(url, data, headers, result)
+}
@@ -0,0 +1,77 @@
+package request
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPackageLevelHelpers 测试包级函数使用默认客户端
+func TestPackageLevelHelpers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Default", r.Header.Get("X-Default"))
+		if r.Method == http.MethodPost {
+			io.Copy(w, r.Body)
+			return
+		}
+		json.NewEncoder(w).Encode(MockResponse{Message: r.URL.Query().Get("q"), Code: 200})
+	}))
+	defer server.Close()
+
+	resp, err := Get(server.URL, map[string]string{"q": "get"}, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp, err = Post(server.URL, []byte("body"), nil)
+	if err != nil || string(resp.Body) != "body" {
+		t.Fatalf("Post failed: %v, body %q", err, resp.Body)
+	}
+	var got MockResponse
+	if err := GetJSON(server.URL, map[string]string{"q": "json"}, nil, &got); err != nil || got.Message != "json" {
+		t.Fatalf("GetJSON failed: %v, got %+v", err, got)
+	}
+	if err := PostJSON(server.URL, MockResponse{Message: "post", Code: 1}, nil, &got); err != nil || got.Message != "post" {
+		t.Fatalf("PostJSON failed: %v, got %+v", err, got)
+	}
+	if DefaultClient().config.Timeout != 30*time.Second || DefaultClient().config.RetryCount != 0 {
+		t.Errorf("Unexpected builtin config: %+v", DefaultClient().config)
+	}
+
+	// 替换默认客户端
+	custom := NewClient(&Config{Timeout: 5 * time.Second, Headers: map[string]string{"X-Default": "custom"}}, nil)
+	SetDefaultClient(custom)
+	defer SetDefaultClient(nil)
+	resp, err = Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("Get with custom client failed: %v", err)
+	}
+	if resp.Headers.Get("X-Default") != "custom" {
+		t.Fatalf("Expected custom default client, headers %v", resp.Headers)
+	}
+
+	// 并发调用与替换
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := Get(server.URL, nil, nil); err != nil {
+				t.Errorf("concurrent Get failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			SetDefaultClient(custom)
+		}()
+	}
+	wg.Wait()
+
+	SetDefaultClient(nil)
+	if DefaultClient() == custom {
+		t.Error("SetDefaultClient(nil) should restore the builtin client")
+	}
+}
@@ -0,0 +1,172 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// ErrResponseTooLarge 响应体超过Config.MaxResponseBytes时返回的错误
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ErrorClass 请求错误分类，用于指标标签等场景
+type ErrorClass int
+
+const (
+	ErrorClassNone    ErrorClass = iota // 无错误
+	ErrorClassClient                    // 客户端错误，4xx状态码
+	ErrorClassServer                    // 服务端错误，5xx状态码
+	ErrorClassTimeout                   // 超时
+	ErrorClassNetwork                   // 网络错误，如DNS解析失败、连接被拒绝
+	ErrorClassUnknown                   // 其他错误，如响应解析失败
+)
+
+// String 返回分类名称，可直接作为指标标签值
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassNone:
+		return "none"
+	case ErrorClassClient:
+		return "client"
+	case ErrorClassServer:
+		return "server"
+	case ErrorClassTimeout:
+		return "timeout"
+	case ErrorClassNetwork:
+		return "network"
+	default:
+		return "unknown"
+	}
+}
+
+// HTTPError JSON等便捷方法收到非预期状态码时返回的错误，可通过errors.As获取状态码、响应体和响应头
+type HTTPError struct {
+	StatusCode int         // 状态码
+	Body       []byte      // 响应体
+	Headers    http.Header // 响应头
+}
+
+// newHTTPError 根据响应创建HTTPError
+func newHTTPError(resp *Response) *HTTPError {
+	return &HTTPError{StatusCode: resp.StatusCode, Body: resp.Body, Headers: resp.Headers}
+}
+
+// Error 实现error接口
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, string(e.Body))
+}
+
+// ValidationError 服务端返回422且响应体能解析出字段错误时，JSON等便捷方法返回的错误
+// 可通过errors.As获取；同时包装了HTTPError，原有按HTTPError判断的代码不受影响
+type ValidationError struct {
+	*HTTPError
+	Fields map[string][]string // 字段名 -> 错误信息列表
+}
+
+// Error 实现error接口，字段按名称排序
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %s", name, strings.Join(e.Fields[name], ", ")))
+	}
+	return fmt.Sprintf("validation failed: %s", strings.Join(parts, "; "))
+}
+
+// Unwrap 返回对应的HTTPError
+func (e *ValidationError) Unwrap() error {
+	return e.HTTPError
+}
+
+// DecodeValidationErrors 默认的字段错误解析函数，响应体格式为 {"errors":{"field":["msg"]}}
+func DecodeValidationErrors(body []byte) (map[string][]string, error) {
+	var payload struct {
+		Errors map[string][]string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return payload.Errors, nil
+}
+
+// statusError 根据非预期状态码的响应创建错误
+// 422响应能按Config.ValidationErrorDecoder解析出字段错误时返回*ValidationError，否则返回*HTTPError
+func (c *Client) statusError(resp *Response) error {
+	httpErr := newHTTPError(resp)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		return httpErr
+	}
+	decode := c.config.ValidationErrorDecoder
+	if decode == nil {
+		decode = DecodeValidationErrors
+	}
+	fields, err := decode(resp.Body)
+	if err != nil || len(fields) == 0 {
+		return httpErr
+	}
+	return &ValidationError{HTTPError: httpErr, Fields: fields}
+}
+
+// IsTimeout 判断错误是否为超时（ctx超时或网络读写超时）
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnRefused 判断错误是否为连接被拒绝
+func IsConnRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// ClassifyError 根据请求返回的响应和错误进行分类
+// 错误优先于响应：重试耗尽时可能同时返回上一次的响应和最后一次的错误，此时以最后一次的错误为准
+func ClassifyError(resp *Response, err error) ErrorClass {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case IsTimeout(err):
+			return ErrorClassTimeout
+		case IsConnRefused(err), errors.As(err, &netErr):
+			return ErrorClassNetwork
+		}
+		// JSON等便捷方法不返回响应，状态码在HTTPError中
+		var httpErr *HTTPError
+		if resp == nil && errors.As(err, &httpErr) {
+			resp = &Response{StatusCode: httpErr.StatusCode}
+		}
+		if resp == nil {
+			return ErrorClassUnknown
+		}
+	}
+	if resp == nil {
+		return ErrorClassNone
+	}
+
+	switch {
+	case resp.StatusCode >= 500:
+		return ErrorClassServer
+	case resp.StatusCode >= 400:
+		return ErrorClassClient
+	}
+	if err != nil {
+		return ErrorClassUnknown
+	}
+	return ErrorClassNone
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestClassifyError 测试错误分类
+func TestClassifyError(t *testing.T) {
+	// 真实的超时错误
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+	_, timeoutErr := NewClient(&Config{Timeout: 20 * time.Millisecond}, nil).Get(server.URL, nil, nil)
+	if timeoutErr == nil {
+		t.Fatal("Expected timeout error")
+	}
+
+	dnsErr := &url.Error{Op: "Get", URL: "http://example.invalid", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true},
+	}}
+
+	tests := []struct {
+		name string
+		resp *Response
+		err  error
+		want ErrorClass
+	}{
+		{"ok", &Response{StatusCode: http.StatusOK}, nil, ErrorClassNone},
+		{"404", &Response{StatusCode: http.StatusNotFound}, nil, ErrorClassClient},
+		{"503", &Response{StatusCode: http.StatusServiceUnavailable}, nil, ErrorClassServer},
+		{"timeout", nil, timeoutErr, ErrorClassTimeout},
+		{"dns", nil, dnsErr, ErrorClassNetwork},
+		{"other", nil, errors.New("failed to read response body"), ErrorClassUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.resp, tt.err); got != tt.want {
+			t.Errorf("%s: ClassifyError() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestHTTPError 测试JSON便捷方法返回可通过errors.As提取的HTTPError
+func TestHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"not found"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+	var result MockResponse
+	err := client.GetJSON(server.URL, nil, nil, &result)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", httpErr.StatusCode)
+	}
+	if string(httpErr.Body) != `{"error":"not found"}` {
+		t.Errorf("Unexpected body: %s", httpErr.Body)
+	}
+	if httpErr.Headers.Get("X-Request-ID") != "req-1" {
+		t.Errorf("Expected X-Request-ID header, got %v", httpErr.Headers)
+	}
+	if err.Error() != `unexpected status code: 404, body: {"error":"not found"}` {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+	if ClassifyError(nil, err) != ErrorClassClient {
+		t.Errorf("Expected HTTPError 404 to be classified as client, got %s", ClassifyError(nil, err))
+	}
+
+	if err := client.PostJSON(server.URL, MockResponse{}, nil, &result); !errors.As(err, &httpErr) {
+		t.Errorf("Expected PostJSON to return HTTPError, got %v", err)
+	}
+}
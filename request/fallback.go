@@ -0,0 +1,63 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// doWithFallback 执行请求，当前主机重试耗尽后仍为网络错误或5xx时，依次切换到Config.FallbackBaseURLs中的主机
+// 与重试相同，非幂等请求只有在允许重试时才会切换
+func (c *Client) doWithFallback(req *http.Request) (*Response, error) {
+	stats := newAttemptStats()
+	resp, err := c.doWithRetry(req, stats)
+	for _, baseURL := range c.config.FallbackBaseURLs {
+		if !c.retryAllowed(req) || !shouldFailover(req, resp, err) {
+			break
+		}
+		next, buildErr := withBaseURL(req, baseURL)
+		if buildErr != nil {
+			return resp, buildErr
+		}
+		c.logf(req.Context(), "Failing over request to %s", next.URL)
+		resp, err = c.doWithRetry(next, stats)
+	}
+	return resp, err
+}
+
+// shouldFailover 判断是否切换到备用主机：ctx未结束，且结果为可重试的网络错误、5xx响应或当前主机已熔断
+// 熔断器按主机划分，备用主机不受当前主机熔断的影响；请求体无法重放时不切换
+func shouldFailover(req *http.Request, resp *Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return errors.Is(err, ErrCircuitOpen) || isRetryableError(err)
+	}
+	return resp != nil && resp.StatusCode >= http.StatusInternalServerError
+}
+
+// withBaseURL 复制请求，将协议和主机替换为baseURL的，路径和查询参数保持不变
+func withBaseURL(req *http.Request, baseURL string) (*http.Request, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid fallback base URL %q", baseURL)
+	}
+	next := req.Clone(req.Context())
+	next.URL.Scheme = base.Scheme
+	next.URL.Host = base.Host
+	next.URL.User = base.User
+	next.Host = base.Host
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request body: %w", err)
+		}
+		next.Body = body
+	}
+	return next, nil
+}
@@ -0,0 +1,125 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFallbackBaseURLs 测试主机持续失败时切换到备用主机，并保留路径、查询参数和请求体
+func TestFallbackBaseURLs(t *testing.T) {
+	var primaryCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+
+	var gotPath, gotQuery, gotBody string
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Write([]byte("ok"))
+	}))
+	defer fallback.Close()
+
+	// 第一个备用主机不可达，继续切换到下一个
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	client := NewClient(&Config{
+		Timeout:            5 * time.Second,
+		RetryCount:         1,
+		RetryUnsafeMethods: true,
+		FallbackBaseURLs:   []string{downURL, fallback.URL},
+	}, nil)
+	recordSleeps(client)
+
+	resp, err := client.Post(primary.URL+"/api/items?id=1&tag=a", []byte("payload"), nil)
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != "ok" {
+		t.Fatalf("Unexpected response: %d %s", resp.StatusCode, resp.Body)
+	}
+	if n := atomic.LoadInt32(&primaryCalls); n != 2 {
+		t.Errorf("Expected primary to be retried before failover, got %d calls", n)
+	}
+	if gotPath != "/api/items" || gotQuery != "id=1&tag=a" || gotBody != "payload" {
+		t.Errorf("Fallback got path %q query %q body %q", gotPath, gotQuery, gotBody)
+	}
+}
+
+// TestFallbackNotOnClientError 测试4xx响应不切换备用主机
+func TestFallbackNotOnClientError(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer primary.Close()
+	var fallbackCalls int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fallbackCalls, 1)
+	}))
+	defer fallback.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second, FallbackBaseURLs: []string{fallback.URL}}, nil)
+	resp, err := client.Get(primary.URL, nil, nil)
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected 404 from primary, got %v %v", resp, err)
+	}
+	if atomic.LoadInt32(&fallbackCalls) != 0 {
+		t.Error("Fallback should not be used for 4xx responses")
+	}
+}
+
+// TestFallbackWithCircuitBreaker 测试主机熔断后仍切换到备用主机，备用主机使用各自的熔断器
+func TestFallbackWithCircuitBreaker(t *testing.T) {
+	var primaryCalls, fallbackCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fallbackCalls, 1)
+		w.Write([]byte("ok"))
+	}))
+	defer fallback.Close()
+
+	client := NewClient(&Config{
+		Timeout:          5 * time.Second,
+		RetryCount:       1,
+		CircuitBreaker:   &CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute},
+		FallbackBaseURLs: []string{fallback.URL},
+	}, nil)
+	recordSleeps(client)
+
+	// 第一次请求使主机熔断，第二次请求主机直接快速失败，两次都由备用主机返回
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(primary.URL+"/api", nil, nil)
+		if err != nil {
+			t.Fatalf("Get %d failed: %v", i, err)
+		}
+		if resp.StatusCode != http.StatusOK || string(resp.Body) != "ok" {
+			t.Fatalf("Get %d: unexpected response %d %s", i, resp.StatusCode, resp.Body)
+		}
+	}
+	if n := atomic.LoadInt32(&primaryCalls); n != 2 {
+		t.Errorf("Expected 2 primary calls before the circuit opened, got %d", n)
+	}
+	if n := atomic.LoadInt32(&fallbackCalls); n != 2 {
+		t.Errorf("Expected 2 fallback calls, got %d", n)
+	}
+	if state := client.CircuitState(primary.Listener.Addr().String()); state != CircuitOpen {
+		t.Errorf("Expected primary circuit open, got %s", state)
+	}
+	if state := client.CircuitState(fallback.Listener.Addr().String()); state != CircuitClosed {
+		t.Errorf("Expected fallback circuit closed, got %s", state)
+	}
+}
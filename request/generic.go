@@ -0,0 +1,16 @@
+package request
+
+// GetInto 执行GET请求并将JSON响应解析为T，是GetJSON的泛型版本
+// Go的方法不能声明类型参数，因此以包级函数的形式提供
+func GetInto[T any](c *Client, url string, params map[string]string, headers map[string]string) (T, error) {
+	var result T
+	err := c.GetJSON(url, params, headers, &result)
+	return result, err
+}
+
+// PostInto 执行POST请求并将JSON响应解析为T，是PostJSON的泛型版本
+func PostInto[T any](c *Client, url string, data interface{}, headers map[string]string) (T, error) {
+	var result T
+	err := c.PostJSON(url, data, headers, &result)
+	return result, err
+}
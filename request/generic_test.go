@@ -0,0 +1,58 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGetInto 测试GET请求解析为结构体和切片
+func TestGetInto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/list" {
+			json.NewEncoder(w).Encode([]MockResponse{{Message: "a", Code: 1}, {Message: "b", Code: 2}})
+			return
+		}
+		json.NewEncoder(w).Encode(MockResponse{Message: "one", Code: 200})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+
+	one, err := GetInto[MockResponse](client, server.URL+"/one", nil, nil)
+	if err != nil {
+		t.Fatalf("GetInto failed: %v", err)
+	}
+	if one.Message != "one" || one.Code != 200 {
+		t.Errorf("Unexpected struct result: %+v", one)
+	}
+
+	list, err := GetInto[[]MockResponse](client, server.URL+"/list", nil, nil)
+	if err != nil {
+		t.Fatalf("GetInto failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Message != "a" || list[1].Code != 2 {
+		t.Errorf("Unexpected slice result: %+v", list)
+	}
+}
+
+// TestPostInto 测试POST请求解析为结构体
+func TestPostInto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req MockResponse
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(MockResponse{Message: "echo:" + req.Message, Code: 201})
+	}))
+	defer server.Close()
+
+	result, err := PostInto[MockResponse](NewClient(nil, nil), server.URL, MockResponse{Message: "hi"}, nil)
+	if err != nil {
+		t.Fatalf("PostInto failed: %v", err)
+	}
+	if result.Message != "echo:hi" || result.Code != 201 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,38 @@
+package request
+
+import (
+	"net/http"
+)
+
+// Interceptor 请求拦截器，包裹在Do外层，用于注入认证刷新、指标、链路追踪等横切逻辑
+// next执行后续拦截器及实际请求（含重试与备用主机切换）；不调用next可直接返回结果（短路）
+// 可多次调用next，如收到401后刷新令牌再重发一次；请求体可重放时（Do会缓存请求体）自动重放
+type Interceptor func(req *http.Request, next func(*http.Request) (*Response, error)) (*Response, error)
+
+// intercept 按Config.Interceptors的顺序包裹do执行请求，第一个拦截器位于最外层
+func (c *Client) intercept(req *http.Request, do func(*http.Request) (*Response, error)) (*Response, error) {
+	if len(c.config.Interceptors) == 0 {
+		return do(req)
+	}
+
+	sent := false
+	next := func(r *http.Request) (*Response, error) {
+		// 再次发送时请求体已被读取，通过GetBody重放
+		if sent && r.GetBody != nil && r.Body != nil && r.Body != http.NoBody {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		sent = true
+		return do(r)
+	}
+	for i := len(c.config.Interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := c.config.Interceptors[i], next
+		next = func(r *http.Request) (*Response, error) {
+			return interceptor(r, inner)
+		}
+	}
+	return next(req)
+}
@@ -0,0 +1,108 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestInterceptors 测试添加请求头与短路的拦截器按顺序组合
+func TestInterceptors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(r.Header.Get("X-Trace") + "|" + r.Header.Get("X-Tenant")))
+	}))
+	defer server.Close()
+
+	var order []string
+	addHeader := func(name, value string) Interceptor {
+		return func(req *http.Request, next func(*http.Request) (*Response, error)) (*Response, error) {
+			order = append(order, name)
+			req.Header.Set(name, value)
+			return next(req)
+		}
+	}
+	shortCircuit := func(req *http.Request, next func(*http.Request) (*Response, error)) (*Response, error) {
+		order = append(order, "cache")
+		if req.URL.Path == "/cached" {
+			return &Response{StatusCode: http.StatusOK, Body: []byte("cached:" + req.Header.Get("X-Trace"))}, nil
+		}
+		return next(req)
+	}
+
+	client := NewClient(&Config{
+		Timeout:      5 * time.Second,
+		Interceptors: []Interceptor{addHeader("X-Trace", "t1"), shortCircuit, addHeader("X-Tenant", "acme")},
+	}, nil)
+
+	resp, err := client.Get(server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if string(resp.Body) != "t1|acme" {
+		t.Errorf("Expected both headers, got %s", resp.Body)
+	}
+	if fmt.Sprint(order) != "[X-Trace cache X-Tenant]" {
+		t.Errorf("Unexpected interceptor order: %v", order)
+	}
+
+	// 短路时外层拦截器仍然生效，内层拦截器与实际请求均不执行
+	order = nil
+	resp, err = client.Get(server.URL+"/cached", nil, nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if string(resp.Body) != "cached:t1" {
+		t.Errorf("Expected short-circuited response, got %s", resp.Body)
+	}
+	if len(order) != 2 {
+		t.Errorf("Expected inner interceptor to be skipped, got %v", order)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected 1 server call, got %d", n)
+	}
+}
+
+// TestInterceptorReauth 测试收到401后刷新令牌并重发一次，请求体自动重放
+func TestInterceptorReauth(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	token := "stale"
+	reauth := func(req *http.Request, next func(*http.Request) (*Response, error)) (*Response, error) {
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := next(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+		token = "fresh"
+		req.Header.Set("Authorization", "Bearer "+token)
+		return next(req)
+	}
+
+	client := NewClient(&Config{Timeout: 5 * time.Second, Interceptors: []Interceptor{reauth}}, nil)
+	resp, err := client.Post(server.URL, []byte(`{"a":1}`), nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after reauth, got %d", resp.StatusCode)
+	}
+	if len(bodies) != 2 || bodies[0] != `{"a":1}` || bodies[1] != `{"a":1}` {
+		t.Errorf("Expected body replayed on both attempts, got %q", bodies)
+	}
+}
@@ -0,0 +1,93 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// MultipartPart multipart请求中的一个部分
+type MultipartPart struct {
+	Name        string    // 表单字段名
+	FileName    string    // 文件名，可选，设置后该部分作为文件上传
+	ContentType string    // 内容类型，为空时文件部分默认application/octet-stream，其他部分不设置
+	Reader      io.Reader // 内容读取器
+}
+
+// quoteEscaper 转义Content-Disposition中的特殊字符
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// header 生成该部分的MIME头
+func (p MultipartPart) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(p.Name))
+	if p.FileName != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(p.FileName))
+	}
+	h.Set("Content-Disposition", disposition)
+
+	contentType := p.ContentType
+	if contentType == "" && p.FileName != "" {
+		contentType = "application/octet-stream"
+	}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	return h
+}
+
+// PostMultipart 发送由任意部分组成的multipart请求，如JSON元数据加二进制文件
+// 请求体通过io.Pipe流式写入，不会整体读入内存；因此请求体无法重放，该请求不会重试
+func (c *Client) PostMultipart(url string, parts []MultipartPart, headers map[string]string) (*Response, error) {
+	for i, part := range parts {
+		if part.Name == "" {
+			return nil, fmt.Errorf("part %d: Name must be provided", i)
+		}
+		if part.Reader == nil {
+			return nil, fmt.Errorf("part %d: Reader must be provided", i)
+		}
+	}
+
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+
+	// 在单独的协程中写入请求体
+	go func() {
+		for _, part := range parts {
+			partWriter, err := w.CreatePart(part.header())
+			if err != nil {
+				pw.CloseWithError(fmt.Errorf("failed to create part %s: %w", part.Name, err))
+				return
+			}
+			if _, err = io.Copy(partWriter, part.Reader); err != nil {
+				pw.CloseWithError(fmt.Errorf("failed to copy content for part %s: %w", part.Name, err))
+				return
+			}
+		}
+		pw.CloseWithError(w.Close())
+	}()
+
+	req, err := http.NewRequestWithContext(c.config.Context, "POST", url, pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// 设置请求头
+	c.setRequestHeaders(req)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := c.intercept(req, func(r *http.Request) (*Response, error) {
+		return c.doWithRetry(r, newAttemptStats())
+	})
+	// 请求提前失败时关闭管道，避免写入协程阻塞
+	pr.CloseWithError(errors.New("request finished"))
+	return resp, err
+}
@@ -0,0 +1,149 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPostMultipart 测试发送JSON部分与文件部分混合的multipart请求
+func TestPostMultipart(t *testing.T) {
+	fileContent := []byte{0x89, 0x50, 0x4e, 0x47, 0x00, 0x01}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("Expected multipart request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// 第一部分：JSON元数据
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Failed to read metadata part: %v", err)
+		}
+		if part.FormName() != "metadata" || part.FileName() != "" {
+			t.Errorf("Unexpected metadata part: name=%s filename=%s", part.FormName(), part.FileName())
+		}
+		if ct := part.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected metadata Content-Type application/json, got %s", ct)
+		}
+		var meta map[string]string
+		if err := json.NewDecoder(part).Decode(&meta); err != nil || meta["title"] != "logo" {
+			t.Errorf("Unexpected metadata: %v, err: %v", meta, err)
+		}
+
+		// 第二部分：二进制文件
+		part, err = reader.NextPart()
+		if err != nil {
+			t.Fatalf("Failed to read file part: %v", err)
+		}
+		if part.FormName() != "file" || part.FileName() != "logo.png" {
+			t.Errorf("Unexpected file part: name=%s filename=%s", part.FormName(), part.FileName())
+		}
+		if ct := part.Header.Get("Content-Type"); ct != "image/png" {
+			t.Errorf("Expected file Content-Type image/png, got %s", ct)
+		}
+		data, _ := io.ReadAll(part)
+		if !bytes.Equal(data, fileContent) {
+			t.Errorf("Unexpected file content: %v", data)
+		}
+
+		if _, err = reader.NextPart(); err != io.EOF {
+			t.Errorf("Expected only 2 parts, got err: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"message":"ok"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	resp, err := client.PostMultipart(server.URL+"/upload", []MultipartPart{
+		{Name: "metadata", ContentType: "application/json", Reader: strings.NewReader(`{"title":"logo"}`)},
+		{Name: "file", FileName: "logo.png", ContentType: "image/png", Reader: bytes.NewReader(fileContent)},
+	}, nil)
+	if err != nil {
+		t.Fatalf("PostMultipart failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+// TestPostMultipartNoRetry 测试流式请求体不会重试
+func TestPostMultipartNoRetry(t *testing.T) {
+	rt := &mockTransport{statuses: []int{http.StatusServiceUnavailable}, body: `{}`}
+	client := NewClientWithTransport(&Config{Timeout: 5 * time.Second, RetryCount: 2}, rt)
+
+	resp, err := client.PostMultipart("http://mock.local/upload", []MultipartPart{
+		{Name: "field", Reader: strings.NewReader("value")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("PostMultipart failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", resp.StatusCode)
+	}
+	if rt.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", rt.calls)
+	}
+}
+
+// TestUploadFilesWithFields 测试表单字段携带指定的Content-Type，并按顺序写在文件之前
+func TestUploadFilesWithFields(t *testing.T) {
+	type partInfo struct {
+		name, fileName, contentType, body string
+	}
+	var parts []partInfo
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Errorf("Expected multipart request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("Failed to read part: %v", err)
+				return
+			}
+			data, _ := io.ReadAll(part)
+			parts = append(parts, partInfo{part.FormName(), part.FileName(), part.Header.Get("Content-Type"), string(data)})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	fields := []FormField{
+		{Name: "metadata", Value: `{"title":"report"}`, ContentType: "application/json"},
+		{Name: "note", Value: "plain"},
+	}
+	files := []FileInfo{{FieldName: "file", FileName: "report.txt", Reader: strings.NewReader("content")}}
+	if _, err := client.UploadFilesWithFields(server.URL, files, fields, nil); err != nil {
+		t.Fatalf("UploadFilesWithFields failed: %v", err)
+	}
+
+	want := []partInfo{
+		{"metadata", "", "application/json", `{"title":"report"}`},
+		{"note", "", "", "plain"},
+		{"file", "report.txt", "text/plain; charset=utf-8", "content"},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("Expected %d parts, got %d: %+v", len(want), len(parts), parts)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("Part %d: expected %+v, got %+v", i, want[i], parts[i])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrRateLimited 配置了Config.RateLimiter且未开启RateLimitWait时，超过客户端限流返回的错误
+var ErrRateLimited = errors.New("client rate limit exceeded")
+
+// rateLimitPollInterval RateLimitWait模式下被限流后再次尝试获取令牌的间隔
+const rateLimitPollInterval = 10 * time.Millisecond
+
+// waitRateLimit 发送请求前向Config.RateLimiter获取令牌，未配置时直接返回
+// 限流键为Config.RateLimitKey，为空时使用请求的主机名（含端口），即按主机分别限流
+func (c *Client) waitRateLimit(req *http.Request) error {
+	if c.config.RateLimiter == nil {
+		return nil
+	}
+	key := c.config.RateLimitKey
+	if key == "" {
+		key = req.URL.Host
+	}
+	ctx := req.Context()
+	for {
+		allowed, _, err := c.config.RateLimiter.Allow(ctx, key)
+		if err != nil {
+			return fmt.Errorf("rate limiter: %w", err)
+		}
+		if allowed {
+			return nil
+		}
+		if !c.config.RateLimitWait {
+			return ErrRateLimited
+		}
+		if err := c.sleep(ctx, rateLimitPollInterval); err != nil {
+			return err
+		}
+	}
+}
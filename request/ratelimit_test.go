@@ -0,0 +1,120 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/limiter"
+)
+
+// TestRateLimiterReject 测试超过限流时直接返回ErrRateLimited，且不发送请求
+func TestRateLimiterReject(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	fake := limiter.NewFakeLimiter(true).SetLimit(2)
+	client := NewClient(&Config{Timeout: 5 * time.Second, RateLimiter: fake}, nil)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(server.URL, nil, nil); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+	if _, err := client.Get(server.URL, nil, nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", n)
+	}
+
+	// 默认按主机限流，指定RateLimitKey后使用独立的计数
+	u, _ := url.Parse(server.URL)
+	if allowed, _, _ := fake.Allow(context.Background(), u.Host); allowed {
+		t.Error("Expected host key to be exhausted")
+	}
+	client = NewClient(&Config{Timeout: 5 * time.Second, RateLimiter: fake, RateLimitKey: "tenant-a"}, nil)
+	if _, err := client.Get(server.URL, nil, nil); err != nil {
+		t.Errorf("Expected request with separate key to pass, got %v", err)
+	}
+}
+
+// TestRateLimiterWait 测试RateLimitWait模式下请求按限流间隔依次发送，ctx取消时停止等待
+func TestRateLimiterWait(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	// 每秒20个令牌、容量1：第一个请求使用初始令牌，之后每50ms放行一个
+	interval := 50 * time.Millisecond
+	bucket := limiter.NewKeyedMemoryBucket(20, 1)
+	defer bucket.Close()
+	client := NewClient(&Config{
+		Timeout:       5 * time.Second,
+		RateLimiter:   bucket,
+		RateLimitWait: true,
+	}, nil)
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(server.URL, nil, nil); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < interval-5*time.Millisecond {
+			t.Errorf("Requests %d and %d only %v apart, want >= %v", i-1, i, gap, interval)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetCtx(ctx, server.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while waiting, got %v", err)
+	}
+}
+
+// TestRateLimiterHalfOpenBreaker 测试半开状态下被限流的请求不占用探测名额、不计入熔断，限流解除后探测请求正常恢复熔断器
+func TestRateLimiterHalfOpenBreaker(t *testing.T) {
+	rt := &mockTransport{statuses: []int{500, 200}, body: `{}`}
+	client, clock := newBreakerClient(rt, CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Second}, 0)
+	client.Get("http://mock.local/down", nil, nil)
+	clock.now = clock.now.Add(time.Second)
+
+	// 冷却结束后被限流：Get与GetStream都返回ErrRateLimited，熔断器保持待探测
+	client.config.RateLimiter = limiter.NewFakeLimiter(false)
+	if _, err := client.Get("http://mock.local/up", nil, nil); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if _, _, err := client.GetStream("http://mock.local/up", nil, nil); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited from GetStream, got %v", err)
+	}
+	if state := client.CircuitState("mock.local"); state != CircuitOpen {
+		t.Errorf("Expected circuit to stay open awaiting a probe, got %s", state)
+	}
+
+	client.config.RateLimiter = limiter.NewFakeLimiter(true)
+	resp, err := client.Get("http://mock.local/up", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected probe to be sent, got %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || client.CircuitState("mock.local") != CircuitClosed {
+		t.Errorf("Expected successful probe to close circuit, got status %d state %s", resp.StatusCode, client.CircuitState("mock.local"))
+	}
+	if rt.calls != 2 {
+		t.Errorf("Expected 2 requests to reach the transport, got %d", rt.calls)
+	}
+}
@@ -0,0 +1,30 @@
+package request
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxDrainBytes DrainBody最多读取的字节数，超过后直接关闭连接，避免为复用连接读取过大的响应体
+const maxDrainBytes = 4 << 20
+
+// DoRaw 执行请求并返回原始响应，不读取响应体，也不重试
+// 会设置全局请求头、幂等键并按代理池策略选择代理
+// 调用方负责处理resp.Body：需要内容时读取后关闭，不关心内容时调用DrainBody，
+// 否则底层连接无法被复用
+func (c *Client) DoRaw(req *http.Request) (*http.Response, error) {
+	c.setRequestHeaders(req)
+	c.setIdempotencyKey(req)
+
+	return c.httpClient.Do(req)
+}
+
+// DrainBody 读取并丢弃剩余的响应体后关闭，使keep-alive连接可以被复用
+// 适用于webhook等不关心响应内容的调用；响应体超过4MB时不再读取，直接关闭
+func DrainBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	resp.Body.Close()
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDrainBodyReusesConnection 测试DrainBody后连接被复用
+func TestDrainBodyReusesConnection(t *testing.T) {
+	var newConns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(strings.Repeat("x", 64*1024)))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest("POST", server.URL+"/webhook", strings.NewReader(`{"event":"ping"}`))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := client.DoRaw(req)
+		if err != nil {
+			t.Fatalf("DoRaw failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		DrainBody(resp)
+	}
+
+	if n := atomic.LoadInt32(&newConns); n != 1 {
+		t.Errorf("Expected 1 connection to be reused, got %d connections", n)
+	}
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -11,33 +12,69 @@ import (
 	"io"
 	"log"
 	"math/rand"
-	"mime/multipart"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lwy110193/go_vendor/cache"
+	"github.com/lwy110193/go_vendor/limiter"
 	mylog "github.com/lwy110193/go_vendor/log"
 )
 
 // Config 请求配置结构体
 type Config struct {
-	Timeout            time.Duration     // 超时时间
-	RetryCount         int               // 重试次数
-	RetryDelay         time.Duration     // 重试间隔
-	Headers            map[string]string // 全局请求头
-	Context            context.Context   // 上下文，可用于取消请求
-	ProxyURL           string            // 代理URL，如 "http://127.0.0.1:8080"
-	ProxyURLs          []string          // 代理URL列表，用于代理池轮询
-	ProxyPoolStrategy  string            // 代理池策略: "round-robin"(默认), "random", "weighted"
-	ProxyWeights       []int             // 代理权重列表，与ProxyURLs一一对应，仅在weighted策略下使用
-	InsecureSkipVerify bool              // 是否跳过TLS证书验证（不安全，仅用于测试环境）
-	TLSConfig          *tls.Config       // 自定义TLS配置
-	ClientCertFile     string            // 客户端证书文件路径
-	ClientKeyFile      string            // 客户端私钥文件路径
-	CAFile             string            // CA证书文件路径
+	Timeout                time.Duration                                  `yaml:"timeout"`                 // 超时时间
+	RetryCount             int                                            `yaml:"retry_count"`             // 重试次数
+	RetryDelay             time.Duration                                  `yaml:"retry_delay"`             // 重试间隔
+	Headers                map[string]string                              `yaml:"headers"`                 // 全局请求头
+	Context                context.Context                                `yaml:"-"`                       // 上下文，可用于取消请求
+	ProxyURL               string                                         `yaml:"proxy_url"`               // 代理URL，如 "http://127.0.0.1:8080"
+	ProxyURLs              []string                                       `yaml:"proxy_urls"`              // 代理URL列表，用于代理池轮询
+	ProxyPoolStrategy      string                                         `yaml:"proxy_pool_strategy"`     // 代理池策略: "round-robin"(默认), "random", "weighted"
+	ProxyWeights           []int                                          `yaml:"proxy_weights"`           // 代理权重列表，与ProxyURLs一一对应，仅在weighted策略下使用
+	InsecureSkipVerify     bool                                           `yaml:"insecure_skip_verify"`    // 是否跳过TLS证书验证（不安全，仅用于测试环境）
+	TLSConfig              *tls.Config                                    `yaml:"-"`                       // 自定义TLS配置
+	ClientCertFile         string                                         `yaml:"client_cert_file"`        // 客户端证书文件路径
+	ClientKeyFile          string                                         `yaml:"client_key_file"`         // 客户端私钥文件路径
+	CAFile                 string                                         `yaml:"ca_file"`                 // CA证书文件路径
+	Logger                 mylog.LogInterface                             `yaml:"-"`                       // 请求日志（如重试信息），为nil时不输出
+	IdempotencyKeyHeader   string                                         `yaml:"idempotency_key_header"`  // 幂等键请求头名称，如 "Idempotency-Key"，设置后POST/PUT/PATCH请求自动携带，重试时保持不变
+	UserAgent              string                                         `yaml:"user_agent"`              // User-Agent请求头，单次请求或Headers中设置时以其为准
+	DisableDecompression   bool                                           `yaml:"disable_decompression"`   // 禁用响应自动解压(gzip/deflate/br)，禁用后返回原始响应体；未禁用时调用方自行设置Accept-Encoding也会解压
+	CompressRequestBody    bool                                           `yaml:"compress_request_body"`   // Post/Put/Patch/Delete及对应JSON方法的请求体是否gzip压缩，需服务端支持Content-Encoding: gzip
+	CompressMinSize        int                                            `yaml:"compress_min_size"`       // 请求体压缩阈值（字节），小于该值不压缩，默认1024
+	RetryBackoff           string                                         `yaml:"retry_backoff"`           // 重试退避策略: "fixed"(默认), "linear", "exponential"，以RetryDelay为基数
+	MaxRetryDelay          time.Duration                                  `yaml:"max_retry_delay"`         // 重试间隔上限，0表示不限制
+	RetryJitter            bool                                           `yaml:"retry_jitter"`            // 是否为重试间隔添加随机抖动，实际间隔在[delay/2, delay]之间
+	LogRequests            bool                                           `yaml:"log_requests"`            // 是否通过Logger记录每次请求的方法、URL、请求头、状态码及耗时，Authorization、Cookie等请求头会脱敏
+	BasicAuthUser          string                                         `yaml:"basic_auth_user"`         // Basic认证用户名，设置后自动添加Authorization请求头
+	BasicAuthPass          string                                         `yaml:"basic_auth_pass"`         // Basic认证密码
+	BearerToken            string                                         `yaml:"bearer_token"`            // Bearer令牌，设置后自动添加Authorization请求头，优先于Basic认证
+	CircuitBreaker         *CircuitBreakerConfig                          `yaml:"circuit_breaker"`         // 熔断器配置，为nil时不启用；按主机统计，连续失败后发往该主机的请求直接返回ErrCircuitOpen，避免重试放大上游压力
+	FallbackBaseURLs       []string                                       `yaml:"fallback_base_urls"`      // 备用主机列表，如 "https://backup.example.com"；当前主机重试耗尽后仍为网络错误或5xx时依次切换，保留原路径和查询参数
+	MaxResponseBytes       int64                                          `yaml:"max_response_bytes"`      // 响应体（解压后）最大字节数，超过时返回ErrResponseTooLarge且不重试，0表示不限制
+	DialTimeout            time.Duration                                  `yaml:"dial_timeout"`            // 建立TCP连接的超时时间，默认30秒；与Timeout独立，可设置较短的连接超时和较长的整体超时
+	TLSHandshakeTimeout    time.Duration                                  `yaml:"tls_handshake_timeout"`   // TLS握手超时时间，默认10秒
+	MaxIdleConns           int                                            `yaml:"max_idle_conns"`          // 连接池最大空闲连接数，默认100
+	MaxIdleConnsPerHost    int                                            `yaml:"max_idle_conns_per_host"` // 每个主机的最大空闲连接数，默认10；高并发访问同一主机时应调大，避免频繁建连
+	IdleConnTimeout        time.Duration                                  `yaml:"idle_conn_timeout"`       // 空闲连接关闭前的最长保持时间，默认90秒
+	RetryUnsafeMethods     bool                                           `yaml:"retry_unsafe_methods"`    // 是否重试POST/PATCH等非幂等请求，默认不重试以免重复提交；设置了IdempotencyKeyHeader的请求带有幂等键，始终可以重试
+	ValidationErrorDecoder func(body []byte) (map[string][]string, error) `yaml:"-"`                       // 解析422响应体中的字段错误，JSON等便捷方法据此返回ValidationError；为nil时使用DecodeValidationErrors
+	Interceptors           []Interceptor                                  `yaml:"-"`                       // 请求拦截器，按顺序包裹Do，第一个位于最外层；Get/Post及JSON、上传等方法均经过拦截器
+	PropagateTrace         bool                                           `yaml:"propagate_trace"`         // 是否将请求ctx中的OpenTelemetry span写入请求头（默认W3C traceparent），使下游服务加入当前调用链
+	RateLimiter            limiter.Limiter                                `yaml:"-"`                       // 客户端限流器，每次发送请求（包括重试）前调用Allow，为nil时不限流；令牌桶可通过limiter.NewKeyedMemoryBucket/NewKeyedRedisBucket按键使用
+	RateLimitKey           string                                         `yaml:"rate_limit_key"`          // 限流键，为空时使用请求的主机名，即按主机分别限流
+	RateLimitWait          bool                                           `yaml:"rate_limit_wait"`         // 被限流时是否等待直到获得令牌（受ctx控制），默认直接返回ErrRateLimited
+	Cache                  cache.Cache                                    `yaml:"-"`                       // GET响应缓存，为nil时不缓存；Get、GetJSON等GET请求先查缓存，200响应按URL和请求头写入，请求或响应带有Cache-Control: no-store时不缓存
+	CacheTTL               time.Duration                                  `yaml:"cache_ttl"`               // 响应缓存的过期时间，0表示使用Cache自身的默认过期时间
 }
 
 type Logger struct {
@@ -56,9 +93,30 @@ var defaultLogger = &Logger{}
 
 // Response 响应结构体
 type Response struct {
-	StatusCode int         // 状态码
-	Headers    http.Header // 响应头
-	Body       []byte      // 响应体
+	StatusCode int           // 状态码
+	Headers    http.Header   // 响应头
+	Body       []byte        // 响应体
+	Elapsed    time.Duration // 从开始请求到返回的总耗时，包含重试等待及切换备用主机
+	Attempts   int           // 实际发送请求的次数，首次即成功时为1，每次重试加1
+}
+
+// attemptStats 记录一次请求的起始时间和已发送次数，切换备用主机时继续累计
+type attemptStats struct {
+	start    time.Time
+	attempts int
+}
+
+func newAttemptStats() *attemptStats {
+	return &attemptStats{start: time.Now()}
+}
+
+// fill 将耗时和发送次数写入响应
+func (s *attemptStats) fill(resp *Response) *Response {
+	if resp != nil {
+		resp.Elapsed = time.Since(s.start)
+		resp.Attempts = s.attempts
+	}
+	return resp
 }
 
 // Client 请求客户端
@@ -72,6 +130,10 @@ type Client struct {
 	currentIndex  int        // 当前轮询索引
 	random        *rand.Rand // 随机数生成器
 	mu            sync.Mutex // 互斥锁，保护并发访问
+	// sleep 重试等待函数，测试时可替换以记录等待时间
+	sleep func(ctx context.Context, d time.Duration) error
+	// breakers 按主机划分的熔断器，未配置时为nil
+	breakers *circuitBreakers
 }
 
 // NewClient 创建新的客户端
@@ -79,23 +141,7 @@ func NewClient(config *Config, log mylog.LogInterface) *Client {
 	if log == nil {
 		log = defaultLogger
 	}
-
-	if config == nil {
-		config = &Config{Timeout: 30 * time.Second}
-	}
-	if config.ProxyPoolStrategy == "" {
-		config.ProxyPoolStrategy = "round-robin"
-	}
-	if config.Context == nil {
-		config.Context = context.Background()
-	}
-	if config.Headers == nil {
-		config.Headers = make(map[string]string)
-	}
-
-	if config.ProxyPoolStrategy == "weighted" && len(config.ProxyWeights) != len(config.ProxyURLs) {
-		log.FatalLog(config.Context, "Warning: Proxy weights length must match ProxyURLs length\n")
-	}
+	config = initConfig(config, log)
 
 	// 创建TLS配置
 	tlsConfig := &tls.Config{}
@@ -137,14 +183,15 @@ func NewClient(config *Config, log mylog.LogInterface) *Client {
 
 	// 创建带超时配置的Transport
 	transport := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 10,
-		IdleConnTimeout:     90 * time.Second,
-		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        intOrDefault(config.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost: intOrDefault(config.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		IdleConnTimeout:     durationOrDefault(config.IdleConnTimeout, defaultIdleConnTimeout),
+		TLSHandshakeTimeout: durationOrDefault(config.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
 		TLSClientConfig:     tlsConfig,
+		DisableCompression:  config.DisableDecompression,
 		// 连接超时设置
 		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
+			Timeout:   durationOrDefault(config.DialTimeout, defaultDialTimeout),
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
 	}
@@ -160,6 +207,89 @@ func NewClient(config *Config, log mylog.LogInterface) *Client {
 		}
 	}
 
+	client := newClient(config, transport, log)
+	// 未设置单个代理时，由Transport在每次请求时从代理池中选择代理
+	// 连接池按代理区分，切换代理不影响其他代理连接的复用
+	if config.ProxyURL == "" && len(client.proxyURLs) > 0 {
+		transport.Proxy = client.proxyForRequest
+	}
+	return client
+}
+
+// 默认的连接、TLS握手超时时间及连接池参数
+const (
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// durationOrDefault d大于0时返回d，否则返回默认值
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// intOrDefault n大于0时返回n，否则返回默认值
+func intOrDefault(n, def int) int {
+	if n > 0 {
+		return n
+	}
+	return def
+}
+
+// NewClientWithTransport 使用自定义的RoundTripper创建客户端
+// 主要用于测试时注入mock传输层，无需启动真实服务即可模拟响应
+// 重试、请求头等逻辑与NewClient创建的客户端一致，但TLS与代理（包括代理池）相关配置不会生效
+func NewClientWithTransport(config *Config, rt http.RoundTripper) *Client {
+	config = initConfig(config, defaultLogger)
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return newClient(config, rt, defaultLogger)
+}
+
+// NewClientWithHTTPClient 使用已有的http.Client创建客户端，httpClient为nil时等同于NewClient(config, nil)
+// httpClient按原样使用（包括其Transport与Timeout），TLS与代理（包括代理池）配置只在NewClient构建默认Transport时生效
+func NewClientWithHTTPClient(config *Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		return NewClient(config, nil)
+	}
+	config = initConfig(config, defaultLogger)
+	c := newClient(config, httpClient.Transport, defaultLogger)
+	c.httpClient = httpClient
+	return c
+}
+
+// initConfig 填充配置默认值
+func initConfig(config *Config, log mylog.LogInterface) *Config {
+	if config == nil {
+		config = &Config{Timeout: 30 * time.Second}
+	}
+	if config.ProxyPoolStrategy == "" {
+		config.ProxyPoolStrategy = "round-robin"
+	}
+	if config.Context == nil {
+		config.Context = context.Background()
+	}
+	if config.Headers == nil {
+		config.Headers = make(map[string]string)
+	}
+	if config.CompressMinSize <= 0 {
+		config.CompressMinSize = defaultCompressMinSize
+	}
+
+	if config.ProxyPoolStrategy == "weighted" && len(config.ProxyWeights) != len(config.ProxyURLs) {
+		log.FatalLog(config.Context, "Warning: Proxy weights length must match ProxyURLs length\n")
+	}
+	return config
+}
+
+// newClient 基于给定的传输层创建客户端
+func newClient(config *Config, transport http.RoundTripper, log mylog.LogInterface) *Client {
 	// 创建http客户端
 	httpClient := &http.Client{
 		Transport: transport,
@@ -204,20 +334,72 @@ func NewClient(config *Config, log mylog.LogInterface) *Client {
 		currentIndex:  0,
 		random:        rand.New(rand.NewSource(time.Now().UnixNano())),
 		mu:            sync.Mutex{},
+		sleep:         sleepContext,
+		breakers:      newCircuitBreakers(config.CircuitBreaker),
 	}
 }
 
 // setRequestHeaders 设置请求头
+// 优先级：单次请求的请求头 > Config.Headers > Config.UserAgent/BearerToken/BasicAuthUser，已存在的请求头不会被覆盖
 func (c *Client) setRequestHeaders(req *http.Request) {
 	// 设置全局请求头
 	for key, value := range c.config.Headers {
-		req.Header.Set(key, value)
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	// 设置认证信息，Bearer令牌优先
+	if req.Header.Get("Authorization") == "" {
+		if c.config.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.config.BearerToken)
+		} else if c.config.BasicAuthUser != "" {
+			req.SetBasicAuth(c.config.BasicAuthUser, c.config.BasicAuthPass)
+		}
+	}
+
+	// 设置User-Agent，避免使用Go默认的User-Agent
+	if c.config.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
 	}
 
 	// 默认设置Content-Type为application/json
 	if req.Header.Get("Content-Type") == "" && req.Method != "GET" && req.Body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	// 传播链路追踪上下文
+	if c.config.PropagateTrace {
+		injectTrace(req)
+	}
+}
+
+// retryAllowed 判断请求是否允许重试及切换备用主机
+// GET/HEAD/PUT/DELETE/OPTIONS/TRACE是幂等的；其他方法需开启RetryUnsafeMethods或带有幂等键
+func (c *Client) retryAllowed(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	if c.config.RetryUnsafeMethods {
+		return true
+	}
+	return c.config.IdempotencyKeyHeader != "" && req.Header.Get(c.config.IdempotencyKeyHeader) != ""
+}
+
+// setIdempotencyKey 为非幂等请求设置幂等键
+// 每个逻辑请求只生成一次，所有重试使用相同的值，服务端可据此去重；已设置时保留调用方的值
+func (c *Client) setIdempotencyKey(req *http.Request) {
+	if c.config.IdempotencyKeyHeader == "" {
+		return
+	}
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return
+	}
+	if req.Header.Get(c.config.IdempotencyKeyHeader) == "" {
+		req.Header.Set(c.config.IdempotencyKeyHeader, uuid.NewString())
+	}
 }
 
 // getNextProxy 根据策略获取下一个代理URL
@@ -270,6 +452,52 @@ func (c *Client) getNextProxy() (*url.URL, error) {
 	return parsedURL, nil
 }
 
+// logf 通过Config.Logger输出日志，未配置时不输出
+func (c *Client) logf(ctx context.Context, msg string, args ...interface{}) {
+	if c.config.Logger == nil {
+		return
+	}
+	c.config.Logger.WriteLog(ctx, msg, args...)
+}
+
+// redactedHeaders 请求日志中需要脱敏的请求头
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+// logRequest 开启LogRequests时记录一次请求的结果
+func (c *Client) logRequest(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	if !c.config.LogRequests {
+		return
+	}
+	if err != nil {
+		c.logf(req.Context(), "HTTP %s %s headers=%s error=%v elapsed=%s", req.Method, req.URL, formatHeaders(req.Header), err, elapsed)
+		return
+	}
+	c.logf(req.Context(), "HTTP %s %s headers=%s status=%d elapsed=%s", req.Method, req.URL, formatHeaders(req.Header), resp.StatusCode, elapsed)
+}
+
+// formatHeaders 按名称排序格式化请求头，敏感请求头的值替换为[REDACTED]
+func formatHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(header[key], ",")
+		if redactedHeaders[http.CanonicalHeaderKey(key)] {
+			value = "[REDACTED]"
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
 // parseResponse 解析响应
 func (c *Client) parseResponse(resp *http.Response) (*Response, error) {
 	if resp == nil {
@@ -277,10 +505,29 @@ func (c *Client) parseResponse(resp *http.Response) (*Response, error) {
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	var reader io.Reader = resp.Body
+	// HEAD响应没有响应体，保留Content-Encoding、Content-Length等响应头原样
+	if !c.config.DisableDecompression && (resp.Request == nil || resp.Request.Method != http.MethodHead) {
+		decoded, err := decodeBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		reader = decoded
+	}
+
+	// 多读一个字节用于判断是否超过限制
+	limit := c.config.MaxResponseBytes
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
 
 	return &Response{
 		StatusCode: resp.StatusCode,
@@ -301,7 +548,12 @@ var retryableStatusCodes = map[int]bool{
 }
 
 // isRetryableError 判断错误是否可以重试
+// ctx取消或超时引起的错误不重试，直接返回给调用方
 func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
 	// 网络错误通常是可重试的
 	var netErr net.Error
 	if errors.As(err, &netErr) {
@@ -315,67 +567,107 @@ func isRetryableError(err error) bool {
 	return false
 }
 
+// proxyForRequest 作为Transport.Proxy使用，每次请求按代理池策略选择代理
+func (c *Client) proxyForRequest(*http.Request) (*url.URL, error) {
+	proxyURL, err := c.getNextProxy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy: %w", err)
+	}
+	return proxyURL, nil
+}
+
 // Do 执行HTTP请求的通用方法（带重试机制）
+// 请求体未设置GetBody时会先读入内存，以便重试时重放
+// POST/PATCH等非幂等请求默认不重试，见Config.RetryUnsafeMethods
+// 配置了Config.Interceptors时，请求依次经过各拦截器
 func (c *Client) Do(req *http.Request) (*Response, error) {
+	// 缓存请求体，因为body只能读取一次
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		bodyBytes, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+		}
+	}
+	return c.intercept(req, c.doWithFallback)
+}
+
+// DoCtx 使用ctx执行请求，替换req原有的上下文
+// ctx取消或超时会中止进行中的请求及尚未开始的重试
+func (c *Client) DoCtx(ctx context.Context, req *http.Request) (*Response, error) {
+	return c.Do(req.WithContext(ctx))
+}
+
+// doWithRetry 执行请求并按配置重试
+// 请求体无法重放(GetBody为nil，如流式上传)时只执行一次
+// stats 累计发送次数，返回的响应会填充Elapsed和Attempts
+func (c *Client) doWithRetry(req *http.Request, stats *attemptStats) (*Response, error) {
 	// 设置请求头
 	c.setRequestHeaders(req)
+	c.setIdempotencyKey(req)
+	if !c.config.DisableDecompression && req.Method != http.MethodHead && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
 
 	var lastErr error
 	var lastResp *Response
 	retryCount := 0
+	maxRetries := c.config.RetryCount
+	if !c.retryAllowed(req) {
+		maxRetries = 0
+	}
 
 	// 执行请求，支持重试
-	for retryCount <= c.config.RetryCount {
-		// 如果不是第一次尝试，输出重试日志
+	for retryCount <= maxRetries {
+		// 如果不是第一次尝试，重放请求体并输出重试日志
 		if retryCount > 0 {
-			fmt.Printf("Retrying request to %s, attempt %d/%d\n", req.URL, retryCount, c.config.RetryCount)
-		}
-
-		// 复制请求体，因为body只能读取一次
-		var reqBody io.ReadCloser
-		if req.Body != nil {
-			bodyBytes, err := io.ReadAll(req.Body)
-			if err != nil {
-				lastErr = fmt.Errorf("failed to read request body: %w", err)
-				retryCount++
-				continue
+			if req.Body != nil && req.GetBody == nil {
+				break
 			}
-			req.Body.Close()
-			reqBody = io.NopCloser(bytes.NewBuffer(bodyBytes))
-			req.Body = reqBody
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					lastErr = fmt.Errorf("failed to get request body: %w", err)
+					break
+				}
+				req.Body = body
+			}
+			c.logf(req.Context(), "Retrying request to %s, attempt %d/%d", req.URL, retryCount, maxRetries)
 		}
 
-		// 创建一个新的客户端副本，以便动态设置代理
-		reqClient := *c.httpClient
-
-		// 检查是否需要从代理池中选择代理
-		if len(c.proxyURLs) > 0 && c.config.ProxyURL == "" {
-			// 从代理池中获取下一个代理
-			proxyURL, err := c.getNextProxy()
-			if err != nil {
-				lastErr = fmt.Errorf("failed to get proxy: %w", err)
-				retryCount++
-				continue
-			}
-			if proxyURL != nil {
-				// 创建一个新的Transport副本并设置代理
-				reqTransport := *reqClient.Transport.(*http.Transport)
-				reqTransport.Proxy = http.ProxyURL(proxyURL)
-				reqClient.Transport = &reqTransport
-			}
+		// 客户端限流不是上游故障，先于熔断器检查：被限流时不占用半开状态的探测名额，也不计入熔断统计
+		if err := c.waitRateLimit(req); err != nil {
+			lastErr = err
+			break
+		}
+		// 熔断器打开时直接失败
+		if err := c.allowRequest(req); err != nil {
+			lastErr = err
+			break
 		}
 
 		// 执行请求
-		resp, err := reqClient.Do(req)
+		start := time.Now()
+		stats.attempts++
+		resp, err := c.httpClient.Do(req)
+		c.logRequest(req, resp, err, time.Since(start))
+		c.recordResult(req, resp, err)
 
 		// 处理错误
 		if err != nil {
 			lastErr = fmt.Errorf("request failed: %w", err)
 
 			// 如果错误可重试且还可以重试，等待后继续
-			if isRetryableError(err) && retryCount < c.config.RetryCount {
+			if isRetryableError(err) && retryCount < maxRetries {
 				retryCount++
-				time.Sleep(c.config.RetryDelay)
+				if waitErr := c.waitRetry(req.Context(), retryCount); waitErr != nil {
+					lastErr = fmt.Errorf("retry aborted: %w", waitErr)
+					break
+				}
 				continue
 			}
 			break
@@ -385,52 +677,94 @@ func (c *Client) Do(req *http.Request) (*Response, error) {
 		parsedResp, parseErr := c.parseResponse(resp)
 		if parseErr != nil {
 			lastErr = parseErr
+			// 响应体超限时重试也无法成功
+			if errors.Is(parseErr, ErrResponseTooLarge) {
+				break
+			}
 			retryCount++
 			continue
 		}
 
 		// 如果状态码是可重试的，且还可以重试，则重试
-		if retryableStatusCodes[parsedResp.StatusCode] && retryCount < c.config.RetryCount {
+		if retryableStatusCodes[parsedResp.StatusCode] && retryCount < maxRetries {
 			lastResp = parsedResp
 			retryCount++
-			time.Sleep(c.config.RetryDelay)
+			if waitErr := c.waitRetry(req.Context(), retryCount); waitErr != nil {
+				lastErr = fmt.Errorf("retry aborted: %w", waitErr)
+				break
+			}
 			continue
 		}
 
 		// 成功响应，直接返回
-		return parsedResp, nil
+		return stats.fill(parsedResp), nil
 	}
 
 	// 所有重试都失败了，返回最后一次的错误或最后一次的响应
 	if lastResp != nil {
-		return lastResp, lastErr
+		return stats.fill(lastResp), lastErr
 	}
 	return nil, lastErr
 }
 
 // Get 执行GET请求
+// 查询参数会进行URL编码，url中已有的查询参数会保留
 func (c *Client) Get(url string, params map[string]string, headers map[string]string) (*Response, error) {
+	return c.GetWithValuesCtx(c.config.Context, url, valuesFromMap(params), headers)
+}
+
+// GetCtx 使用调用方的ctx执行GET请求，Config.Timeout仍然生效
+// ctx取消会中止进行中的请求及尚未开始的重试
+func (c *Client) GetCtx(ctx context.Context, url string, params map[string]string, headers map[string]string) (*Response, error) {
+	return c.GetWithValuesCtx(ctx, url, valuesFromMap(params), headers)
+}
+
+// GetWithValues 执行GET请求，查询参数使用url.Values，支持同一个键携带多个值（如 tag=a&tag=b）
+// 同一个键的多个值按切片顺序发送
+func (c *Client) GetWithValues(url string, params url.Values, headers map[string]string) (*Response, error) {
+	return c.GetWithValuesCtx(c.config.Context, url, params, headers)
+}
+
+// GetWithValuesCtx 使用调用方的ctx执行GET请求，查询参数使用url.Values
+func (c *Client) GetWithValuesCtx(ctx context.Context, url string, params url.Values, headers map[string]string) (*Response, error) {
+	return c.doWithQuery(ctx, "GET", url, params, headers)
+}
+
+// GetOK 执行GET请求，响应状态码不是2xx时返回*HTTPError（422时可能为*ValidationError），避免调用方遗漏状态码检查
+// 与Get的区别仅在于状态码检查，响应体不做解析
+func (c *Client) GetOK(url string, params map[string]string, headers map[string]string) (*Response, error) {
+	return c.expectSuccess(c.Get(url, params, headers))
+}
+
+// Head 执行HEAD请求，只获取状态码和响应头（如Content-Length、ETag、Last-Modified），Body为空
+// 重试和超时规则与Get相同；不会自动添加Accept-Encoding，响应头保持服务端返回的原样
+func (c *Client) Head(url string, params map[string]string, headers map[string]string) (*Response, error) {
+	return c.doWithQuery(c.config.Context, "HEAD", url, valuesFromMap(params), headers)
+}
+
+// doWithQuery 执行不带请求体的请求，查询参数追加到url
+func (c *Client) doWithQuery(ctx context.Context, method, url string, params url.Values, headers map[string]string) (*Response, error) {
 	// 构建带查询参数的URL
-	fullURL := url
-	if len(params) > 0 {
-		query := ""
-		for key, value := range params {
-			if query == "" {
-				query = "?"
-			} else {
-				query += "\u0026"
-			}
-			query += fmt.Sprintf("%s=%s", key, value)
+	fullURL, err := appendQuery(url, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// 配置了响应缓存的GET请求先查缓存，单次请求头带有Cache-Control: no-store时跳过
+	var cacheKey string
+	if method == "GET" && c.config.Cache != nil && !requestNoStore(headers) {
+		cacheKey = c.responseCacheKey(fullURL, headers)
+		if resp := c.getCachedResponse(ctx, cacheKey); resp != nil {
+			return resp, nil
 		}
-		fullURL += query
 	}
 
 	// 创建带超时的上下文
-	ctx, cancel := context.WithTimeout(c.config.Context, c.config.Timeout)
+	reqCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
 
 	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(reqCtx, method, fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -441,31 +775,121 @@ func (c *Client) Get(url string, params map[string]string, headers map[string]st
 	}
 
 	// 执行请求
-	return c.Do(req)
+	resp, err := c.Do(req)
+	if err == nil && cacheKey != "" {
+		c.storeResponse(ctx, cacheKey, resp)
+	}
+	return resp, err
+}
+
+// valuesFromMap 将map形式的查询参数转换为url.Values
+func valuesFromMap(params map[string]string) url.Values {
+	values := make(url.Values, len(params))
+	for key, value := range params {
+		values.Set(key, value)
+	}
+	return values
+}
+
+// appendQuery 将查询参数编码后追加到URL，与URL中已有的查询参数合并
+func appendQuery(rawURL string, params url.Values) (string, error) {
+	if len(params) == 0 {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url: %w", err)
+	}
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
 }
 
 // GetJSON 执行GET请求并自动解析JSON响应
 func (c *Client) GetJSON(url string, params map[string]string, headers map[string]string, result interface{}) error {
+	_, err := c.GetJSONResp(url, params, headers, result)
+	return err
+}
+
+// GetJSONResp 与GetJSON相同，同时返回原始响应，用于读取分页Link、限流等响应头
+// 收到响应后即使状态码不是200或解析失败，也会同时返回响应与错误
+func (c *Client) GetJSONResp(url string, params map[string]string, headers map[string]string, result interface{}) (*Response, error) {
 	resp, err := c.Get(url, params, headers)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(resp.Body))
+		return resp, c.statusError(resp)
 	}
 
 	// 解析JSON
 	if err := json.Unmarshal(resp.Body, result); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON: %w", err)
+		return resp, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 
-	return nil
+	return resp, nil
 }
 
 // Post 执行POST请求
 func (c *Client) Post(url string, body []byte, headers map[string]string) (*Response, error) {
+	return c.doWithBody(c.config.Context, "POST", url, body, headers)
+}
+
+// PostOK 执行POST请求，响应状态码不是2xx时返回错误，规则同GetOK
+func (c *Client) PostOK(url string, body []byte, headers map[string]string) (*Response, error) {
+	return c.expectSuccess(c.Post(url, body, headers))
+}
+
+// expectSuccess 将非2xx响应转换为错误，请求本身失败时原样返回
+func (c *Client) expectSuccess(resp *Response, err error) (*Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, c.statusError(resp)
+	}
+	return resp, nil
+}
+
+// Put 执行PUT请求
+func (c *Client) Put(url string, body []byte, headers map[string]string) (*Response, error) {
+	return c.doWithBody(c.config.Context, "PUT", url, body, headers)
+}
+
+// Patch 执行PATCH请求
+func (c *Client) Patch(url string, body []byte, headers map[string]string) (*Response, error) {
+	return c.doWithBody(c.config.Context, "PATCH", url, body, headers)
+}
+
+// Delete 执行DELETE请求，body可为nil
+func (c *Client) Delete(url string, body []byte, headers map[string]string) (*Response, error) {
+	return c.doWithBody(c.config.Context, "DELETE", url, body, headers)
+}
+
+// PostCtx 使用调用方的ctx执行POST请求，Config.Timeout仍然生效
+func (c *Client) PostCtx(ctx context.Context, url string, body []byte, headers map[string]string) (*Response, error) {
+	return c.doWithBody(ctx, "POST", url, body, headers)
+}
+
+// doWithBody 执行带请求体的请求
+func (c *Client) doWithBody(ctx context.Context, method, url string, body []byte, headers map[string]string) (*Response, error) {
+	// 按配置压缩请求体，调用方已指定Content-Encoding时不处理
+	compressed := false
+	if c.shouldCompress(body, headers) {
+		gz, err := gzipBody(body)
+		if err != nil {
+			return nil, err
+		}
+		body, compressed = gz, true
+	}
+
 	// 创建请求体
 	var bodyReader io.Reader
 	if body != nil {
@@ -473,11 +897,11 @@ func (c *Client) Post(url string, body []byte, headers map[string]string) (*Resp
 	}
 
 	// 创建带超时的上下文
-	ctx, cancel := context.WithTimeout(c.config.Context, c.config.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
 
 	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -486,59 +910,116 @@ func (c *Client) Post(url string, body []byte, headers map[string]string) (*Resp
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 
 	// 执行请求
 	return c.Do(req)
 }
 
 // PostJSON 执行POST请求并自动序列化为JSON，同时解析响应
+// 请求头Content-Type默认为application/json，可通过headers指定其他类型（如application/vnd.api+json）
 func (c *Client) PostJSON(url string, data interface{}, headers map[string]string, result interface{}) error {
+	return c.doJSON(c.config.Context, "POST", url, data, headers, result)
+}
+
+// PostJSONResp 与PostJSON相同，同时返回原始响应，规则同GetJSONResp
+func (c *Client) PostJSONResp(url string, data interface{}, headers map[string]string, result interface{}) (*Response, error) {
+	return c.doJSONResp(c.config.Context, "POST", url, data, headers, result)
+}
+
+// PutJSON 执行PUT请求并自动序列化为JSON，同时解析响应
+func (c *Client) PutJSON(url string, data interface{}, headers map[string]string, result interface{}) error {
+	return c.doJSON(c.config.Context, "PUT", url, data, headers, result)
+}
+
+// PatchJSON 执行PATCH请求并自动序列化为JSON，同时解析响应
+func (c *Client) PatchJSON(url string, data interface{}, headers map[string]string, result interface{}) error {
+	return c.doJSON(c.config.Context, "PATCH", url, data, headers, result)
+}
+
+// PostJSONCtx 使用调用方的ctx执行JSON POST请求
+func (c *Client) PostJSONCtx(ctx context.Context, url string, data interface{}, headers map[string]string, result interface{}) error {
+	return c.doJSON(ctx, "POST", url, data, headers, result)
+}
+
+// withJSONContentType 返回带Content-Type: application/json的请求头副本
+// 调用方已设置Content-Type（不区分大小写，如application/vnd.api+json）时保留调用方的值；
+// 显式设置后Config.Headers中的Content-Type不会覆盖JSON请求
+func withJSONContentType(headers map[string]string) map[string]string {
+	for key := range headers {
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			return headers
+		}
+	}
+	merged := make(map[string]string, len(headers)+1)
+	for key, value := range headers {
+		merged[key] = value
+	}
+	merged["Content-Type"] = "application/json"
+	return merged
+}
+
+// doJSON 序列化请求数据为JSON并执行请求，状态码为200/201时解析响应
+func (c *Client) doJSON(ctx context.Context, method, url string, data interface{}, headers map[string]string, result interface{}) error {
+	_, err := c.doJSONResp(ctx, method, url, data, headers, result)
+	return err
+}
+
+// doJSONResp doJSON的实现，收到响应时同时返回响应
+func (c *Client) doJSONResp(ctx context.Context, method, url string, data interface{}, headers map[string]string, result interface{}) (*Response, error) {
 	// 序列化请求数据
 	body, err := json.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request data: %w", err)
+		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
-	// 执行POST请求
-	resp, err := c.Post(url, body, headers)
+	// 执行请求
+	resp, err := c.doWithBody(ctx, method, url, body, withJSONContentType(headers))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(resp.Body))
+		return resp, c.statusError(resp)
 	}
 
 	// 如果需要解析响应结果
 	if err := json.Unmarshal(resp.Body, result); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+		return resp, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	return nil
+	return resp, nil
 }
 
 // PostForm 执行表单POST请求
 func (c *Client) PostForm(url string, form map[string]string, headers map[string]string) (*Response, error) {
-	// 构建表单数据
-	var formData bytes.Buffer
-	for key, value := range form {
-		if formData.Len() > 0 {
-			formData.WriteString("\u0026")
-		}
-		formData.WriteString(fmt.Sprintf("%s=%s", key, value))
-	}
+	return c.PostFormValues(url, valuesFromMap(form), headers)
+}
 
-	// 如果没有提供Content-Type，设置为表单格式
-	if headers == nil {
-		headers = make(map[string]string)
+// PostFormValues 执行表单POST请求，支持同一字段多个值（如 tags[]=a&tags[]=b）及嵌套键（如 user[name]=x）
+// 字段名和值均进行URL编码
+func (c *Client) PostFormValues(url string, form url.Values, headers map[string]string) (*Response, error) {
+	// 如果没有提供Content-Type，设置为表单格式；复制请求头，避免修改调用方的map
+	formHeaders := make(map[string]string, len(headers)+1)
+	for key, value := range headers {
+		formHeaders[key] = value
 	}
-	if headers["Content-Type"] == "" {
-		headers["Content-Type"] = "application/x-www-form-urlencoded"
+	if formHeaders["Content-Type"] == "" {
+		formHeaders["Content-Type"] = "application/x-www-form-urlencoded"
 	}
 
 	// 执行POST请求
-	return c.Post(url, formData.Bytes(), headers)
+	return c.Post(url, []byte(form.Encode()), formHeaders)
+}
+
+// FormField multipart表单字段
+type FormField struct {
+	Name        string // 字段名
+	Value       string // 字段值
+	ContentType string // 该部分的Content-Type，如application/json，为空时不设置
 }
 
 // FileInfo 文件信息结构体
@@ -547,143 +1028,109 @@ type FileInfo struct {
 	FileName  string    // 文件名
 	FilePath  string    // 文件路径
 	Reader    io.Reader // 文件内容读取器
-	Size      int64     // 文件大小
+	Size      int64     // 文件大小，用作上传进度的总量，未知时为0
+	// ContentType 文件部分的Content-Type，为空时按文件扩展名推断，无法推断时根据前512字节内容检测
+	ContentType string
+	// ProgressFunc 上传进度回调，可选；totalBytes取Size，Size未设置时为-1
+	// 每发送progressInterval字节及文件发送完成时调用，在写入请求体的协程中执行
+	ProgressFunc func(bytesSent, totalBytes int64)
 }
 
-// UploadFile 上传单个文件
-func (c *Client) UploadFile(url string, file FileInfo, formData map[string]string, headers map[string]string) (*Response, error) {
-	// 创建multipart表单
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-
-	// 添加普通表单字段
-	for key, value := range formData {
-		if err := w.WriteField(key, value); err != nil {
-			return nil, fmt.Errorf("failed to write form field %s: %w", key, err)
-		}
-	}
-
-	// 添加文件字段
-	var fileReader io.Reader
-	var err error
-
-	if file.Reader != nil {
-		fileReader = file.Reader
-	} else if file.FilePath != "" {
-		fileReader, err = os.Open(file.FilePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to open file %s: %w", file.FilePath, err)
-		}
-		defer fileReader.(io.Closer).Close()
-	} else {
-		return nil, errors.New("file Reader or FilePath must be provided")
-	}
-
-	// 创建文件字段
-	part, err := w.CreateFormFile(file.FieldName, file.FileName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
-	}
+// progressInterval 上传进度回调的最小字节间隔
+const progressInterval = 64 << 10
 
-	// 复制文件内容
-	if _, err = io.Copy(part, fileReader); err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
-	}
-
-	// 完成multipart表单
-	if err = w.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
-	}
+// progressReader 读取时累计字节数并按间隔回调上传进度
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	reported int64
+	total    int64
+	fn       func(bytesSent, totalBytes int64)
+}
 
-	// 设置Content-Type
-	if headers == nil {
-		headers = make(map[string]string)
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.sent += int64(n)
+	if r.sent-r.reported >= progressInterval || (err == io.EOF && r.sent > r.reported) {
+		r.reported = r.sent
+		r.fn(r.sent, r.total)
 	}
-	headers["Content-Type"] = w.FormDataContentType()
+	return n, err
+}
 
-	// 创建请求
-	req, err := http.NewRequestWithContext(c.config.Context, "POST", url, &b)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+// UploadFile 上传单个文件
+// 请求体流式写入，文件不会整体读入内存；因此请求体无法重放，该请求不会重试
+func (c *Client) UploadFile(url string, file FileInfo, formData map[string]string, headers map[string]string) (*Response, error) {
+	return c.UploadFiles(url, []FileInfo{file}, formData, headers)
+}
 
-	// 设置请求头
-	c.setRequestHeaders(req)
-	for key, value := range headers {
-		req.Header.Set(key, value)
+// UploadFiles 上传多个文件，普通表单字段在文件之前写入
+// 请求体流式写入，文件不会整体读入内存；因此请求体无法重放，该请求不会重试
+func (c *Client) UploadFiles(url string, files []FileInfo, formData map[string]string, headers map[string]string) (*Response, error) {
+	fields := make([]FormField, 0, len(formData))
+	for key, value := range formData {
+		fields = append(fields, FormField{Name: key, Value: value})
 	}
-
-	// 执行请求
-	return c.Do(req)
+	return c.UploadFilesWithFields(url, files, fields, headers)
 }
 
-// UploadFiles 上传多个文件
-func (c *Client) UploadFiles(url string, files []FileInfo, formData map[string]string, headers map[string]string) (*Response, error) {
-	// 创建multipart表单
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
+// UploadFilesWithFields 上传多个文件，表单字段可单独指定Content-Type（如application/json的元数据字段）
+// 表单字段按切片顺序在文件之前写入；其余规则同UploadFiles
+func (c *Client) UploadFilesWithFields(url string, files []FileInfo, fields []FormField, headers map[string]string) (*Response, error) {
+	parts := make([]MultipartPart, 0, len(fields)+len(files))
 
 	// 添加普通表单字段
-	for key, value := range formData {
-		if err := w.WriteField(key, value); err != nil {
-			return nil, fmt.Errorf("failed to write form field %s: %w", key, err)
-		}
+	for _, field := range fields {
+		parts = append(parts, MultipartPart{Name: field.Name, ContentType: field.ContentType, Reader: strings.NewReader(field.Value)})
 	}
 
-	// 添加所有文件
+	// 添加所有文件，FilePath指定的文件在请求结束后关闭
 	for i, file := range files {
 		var fileReader io.Reader
-		var err error
-
 		if file.Reader != nil {
 			fileReader = file.Reader
 		} else if file.FilePath != "" {
-			fileReader, err = os.Open(file.FilePath)
+			f, err := os.Open(file.FilePath)
 			if err != nil {
 				return nil, fmt.Errorf("failed to open file %s at index %d: %w", file.FilePath, i, err)
 			}
-			defer fileReader.(io.Closer).Close()
+			defer f.Close()
+			fileReader = f
 		} else {
 			return nil, fmt.Errorf("file %d: Reader or FilePath must be provided", i)
 		}
-
-		// 创建文件字段
-		part, err := w.CreateFormFile(file.FieldName, file.FileName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create form file for %s: %w", file.FileName, err)
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(file.FileName))
 		}
-
-		// 复制文件内容
-		if _, err := io.Copy(part, fileReader); err != nil {
-			return nil, fmt.Errorf("failed to copy content for file %s: %w", file.FileName, err)
+		if contentType == "" {
+			var err error
+			if fileReader, contentType, err = sniffContentType(fileReader); err != nil {
+				return nil, fmt.Errorf("failed to read content for file %s: %w", file.FileName, err)
+			}
 		}
+		if file.ProgressFunc != nil {
+			total := file.Size
+			if total <= 0 {
+				total = -1
+			}
+			fileReader = &progressReader{reader: fileReader, total: total, fn: file.ProgressFunc}
+		}
+		parts = append(parts, MultipartPart{Name: file.FieldName, FileName: file.FileName, ContentType: contentType, Reader: fileReader})
 	}
 
-	// 完成multipart表单
-	if err := w.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	// 设置Content-Type
-	if headers == nil {
-		headers = make(map[string]string)
-	}
-	headers["Content-Type"] = w.FormDataContentType()
-
-	// 创建请求
-	req, err := http.NewRequestWithContext(c.config.Context, "POST", url, &b)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	// 执行请求
+	return c.PostMultipart(url, parts, headers)
+}
 
-	// 设置请求头
-	c.setRequestHeaders(req)
-	for key, value := range headers {
-		req.Header.Set(key, value)
+// sniffContentType 根据前512字节检测内容类型，返回的reader仍从头读取
+func sniffContentType(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, "", err
 	}
-
-	// 执行请求
-	return c.Do(req)
+	return br, http.DetectContentType(head), nil
 }
 
 // UploadFileJSON 上传单个文件并自动解析JSON响应
@@ -695,7 +1142,7 @@ func (c *Client) UploadFileJSON(url string, file FileInfo, formData map[string]s
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(resp.Body))
+		return c.statusError(resp)
 	}
 
 	// 解析JSON
@@ -715,7 +1162,7 @@ func (c *Client) UploadFilesJSON(url string, files []FileInfo, formData map[stri
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(resp.Body))
+		return c.statusError(resp)
 	}
 
 	// 解析JSON
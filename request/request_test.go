@@ -2,12 +2,21 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -67,6 +76,96 @@ func TestGet(t *testing.T) {
 	}
 }
 
+// TestGetQueryEncoding 测试查询参数编码及与URL已有参数的合并
+func TestGetQueryEncoding(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+	params := map[string]string{
+		"q":    "a b&c=d",
+		"name": "中文 参数",
+	}
+	if _, err := client.Get(server.URL+"/test?a=1", params, nil); err != nil {
+		t.Fatalf("Get request failed: %v", err)
+	}
+
+	want := map[string]string{"a": "1", "q": "a b&c=d", "name": "中文 参数"}
+	if len(query) != len(want) {
+		t.Errorf("Expected %d query params, got %v", len(want), query)
+	}
+	for key, value := range want {
+		if got := query[key]; len(got) != 1 || got[0] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, got)
+		}
+	}
+}
+
+// TestGetExistingQuery 测试url已带查询参数时追加参数不会产生第二个?，同名参数保留原值并追加新值
+func TestGetExistingQuery(t *testing.T) {
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	tests := []struct {
+		url    string
+		params map[string]string
+		want   string
+	}{
+		{"/y?a=1", map[string]string{"b": "2"}, "a=1&b=2"},
+		{"/y?a=1", map[string]string{"a": "2"}, "a=1&a=2"},
+		{"/y?", map[string]string{"b": "2"}, "b=2"},
+		{"/y?a=1", nil, "a=1"},
+	}
+	for _, tt := range tests {
+		if _, err := client.Get(server.URL+tt.url, tt.params, nil); err != nil {
+			t.Fatalf("Get request failed: %v", err)
+		}
+		if rawQuery != tt.want {
+			t.Errorf("Get %s with %v: expected query %s, got %s", tt.url, tt.params, tt.want, rawQuery)
+		}
+		if _, err := client.Head(server.URL+tt.url, tt.params, nil); err != nil {
+			t.Fatalf("Head request failed: %v", err)
+		}
+		if rawQuery != tt.want {
+			t.Errorf("Head %s with %v: expected query %s, got %s", tt.url, tt.params, tt.want, rawQuery)
+		}
+	}
+}
+
+// TestGetWithValues 测试同一个键携带多个值的查询参数
+func TestGetWithValues(t *testing.T) {
+	var rawQuery string
+	var tags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		tags = r.URL.Query()["tag"]
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+	params := url.Values{"tag": {"a", "b"}}
+	if _, err := client.GetWithValues(server.URL+"/test", params, nil); err != nil {
+		t.Fatalf("GetWithValues request failed: %v", err)
+	}
+
+	if rawQuery != "tag=a&tag=b" {
+		t.Errorf("Expected raw query tag=a&tag=b, got %s", rawQuery)
+	}
+	if len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+		t.Errorf("Expected tags [a b], got %v", tags)
+	}
+}
+
 // TestGetJSON 测试GET请求自动解析JSON功能
 func TestGetJSON(t *testing.T) {
 	// 创建测试服务器
@@ -134,6 +233,56 @@ func TestPost(t *testing.T) {
 	}
 }
 
+// TestPostFormValues 测试多值字段和嵌套键的表单请求
+func TestPostFormValues(t *testing.T) {
+	var form url.Values
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm failed: %v", err)
+		}
+		form = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+	headers := map[string]string{"X-Test": "1"}
+	values := url.Values{
+		"tags[]":     {"a", "b"},
+		"user[name]": {"张 三&"},
+		"redirect":   {"/cb?x=1&y=2"},
+	}
+	if _, err := client.PostFormValues(server.URL, values, headers); err != nil {
+		t.Fatalf("PostFormValues failed: %v", err)
+	}
+
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Expected form content type, got %s", contentType)
+	}
+	if tags := form["tags[]"]; len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+		t.Errorf("Expected tags[] = [a b], got %v", tags)
+	}
+	if name := form.Get("user[name]"); name != "张 三&" {
+		t.Errorf("Expected user[name] = 张 三&, got %q", name)
+	}
+	if redirect := form.Get("redirect"); redirect != "/cb?x=1&y=2" {
+		t.Errorf("Expected redirect = /cb?x=1&y=2, got %q", redirect)
+	}
+	if len(headers) != 1 {
+		t.Errorf("Caller headers should not be modified, got %v", headers)
+	}
+
+	// map形式的PostForm同样进行编码
+	if _, err := client.PostForm(server.URL, map[string]string{"q": "a=b&c"}, nil); err != nil {
+		t.Fatalf("PostForm failed: %v", err)
+	}
+	if form.Get("q") != "a=b&c" {
+		t.Errorf("Expected q = a=b&c, got %q", form.Get("q"))
+	}
+}
+
 // TestPostJSON 测试POST请求自动序列化和解析JSON功能
 func TestPostJSON(t *testing.T) {
 	// 创建测试服务器
@@ -171,6 +320,74 @@ func TestPostJSON(t *testing.T) {
 	}
 }
 
+// TestPutPatchDelete 测试PUT、PATCH、DELETE请求的方法和请求体
+func TestPutPatchDelete(t *testing.T) {
+	var method, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, body = r.Method, string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+	tests := []struct {
+		method string
+		do     func(string, []byte, map[string]string) (*Response, error)
+		body   string
+	}{
+		{"PUT", client.Put, `{"message":"put"}`},
+		{"PATCH", client.Patch, `{"message":"patch"}`},
+		{"DELETE", client.Delete, ""},
+	}
+	for _, tt := range tests {
+		var reqBody []byte
+		if tt.body != "" {
+			reqBody = []byte(tt.body)
+		}
+		resp, err := tt.do(server.URL+"/test", reqBody, nil)
+		if err != nil {
+			t.Fatalf("%s request failed: %v", tt.method, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected status code 200, got %d", tt.method, resp.StatusCode)
+		}
+		if method != tt.method || body != tt.body {
+			t.Errorf("Expected %s %q, server got %s %q", tt.method, tt.body, method, body)
+		}
+	}
+}
+
+// TestPutPatchJSON 测试PUT、PATCH请求自动序列化和解析JSON功能
+func TestPutPatchJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body MockResponse
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(MockResponse{Message: r.Method + ":" + body.Message, Code: 200})
+	}))
+	defer server.Close()
+
+	client := NewClient(nil, nil)
+
+	var result MockResponse
+	if err := client.PutJSON(server.URL, MockResponse{Message: "a"}, nil, &result); err != nil {
+		t.Fatalf("PutJSON failed: %v", err)
+	}
+	if result.Message != "PUT:a" {
+		t.Errorf("Expected PUT:a, got %s", result.Message)
+	}
+
+	if err := client.PatchJSON(server.URL, MockResponse{Message: "b"}, nil, &result); err != nil {
+		t.Fatalf("PatchJSON failed: %v", err)
+	}
+	if result.Message != "PATCH:b" {
+		t.Errorf("Expected PATCH:b, got %s", result.Message)
+	}
+}
+
 // TestRetry 测试重试机制
 func TestRetry(t *testing.T) {
 	// 计数器，记录请求次数
@@ -216,6 +433,397 @@ func TestRetry(t *testing.T) {
 	}
 }
 
+// TestResponseAttempts 测试响应中的耗时和发送次数
+func TestResponseAttempts(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" && count.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{RetryCount: 3, Timeout: time.Second}, nil)
+	recordSleeps(client)
+
+	resp, err := client.Get(server.URL+"/flaky", nil, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Attempts != 3 || resp.Elapsed <= 0 {
+		t.Errorf("Expected 200 after 3 attempts with Elapsed > 0, got %d, %d, %v", resp.StatusCode, resp.Attempts, resp.Elapsed)
+	}
+
+	resp, err = client.Get(server.URL+"/ok", nil, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if resp.Attempts != 1 || resp.Elapsed <= 0 {
+		t.Errorf("Expected 1 attempt with Elapsed > 0, got %d, %v", resp.Attempts, resp.Elapsed)
+	}
+}
+
+// TestHead 测试HEAD请求返回响应头且响应体为空，可重试状态码按配置重试
+func TestHead(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD, got %s", r.Method)
+		}
+		if r.Header.Get("Accept-Encoding") != "" {
+			t.Errorf("HEAD should not set Accept-Encoding, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.Header().Set("Content-Length", "12345")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{RetryCount: 1, Timeout: time.Second}, nil)
+	recordSleeps(client)
+
+	resp, err := client.Head(server.URL+"/file", map[string]string{"v": "1"}, nil)
+	if err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Attempts != 2 {
+		t.Errorf("Expected 200 after 2 attempts, got %d after %d", resp.StatusCode, resp.Attempts)
+	}
+	if resp.Headers.Get("ETag") != `"v1"` || resp.Headers.Get("Content-Length") != "12345" || resp.Headers.Get("Last-Modified") == "" {
+		t.Errorf("Unexpected headers: %v", resp.Headers)
+	}
+	if len(resp.Body) != 0 {
+		t.Errorf("Expected empty body, got %q", resp.Body)
+	}
+}
+
+// TestDialTimeout 测试连接超时独立于整体超时生效
+// 依赖不可路由地址使连接挂起，网络环境不会丢弃该地址的连接请求时跳过
+func TestDialTimeout(t *testing.T) {
+	const addr = "10.255.255.1:81"
+	if conn, err := net.DialTimeout("tcp", addr, 50*time.Millisecond); err == nil {
+		conn.Close()
+		t.Skip("unroutable address is reachable in this environment")
+	} else if !IsTimeout(err) {
+		t.Skipf("dial to unroutable address did not hang: %v", err)
+	}
+
+	client := NewClient(&Config{Timeout: 10 * time.Second, DialTimeout: 200 * time.Millisecond}, nil)
+	start := time.Now()
+	_, err := client.Get("http://"+addr, nil, nil)
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("Expected dial timeout error")
+	}
+	if elapsed < 150*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected dial timeout around 200ms, took %v", elapsed)
+	}
+}
+
+// TestTLSHandshakeTimeout 测试服务端不响应TLS握手时按TLSHandshakeTimeout超时
+func TestTLSHandshakeTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// 接受连接但不进行握手
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client := NewClient(&Config{Timeout: 10 * time.Second, TLSHandshakeTimeout: 200 * time.Millisecond}, nil)
+	start := time.Now()
+	_, err = client.Get("https://"+ln.Addr().String(), nil, nil)
+	elapsed := time.Since(start)
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Fatalf("Expected TLS handshake timeout, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected handshake timeout around 200ms, took %v", elapsed)
+	}
+}
+
+// TestTransportPoolConfig 测试连接池参数传递到Transport，未设置时使用默认值
+func TestTransportPoolConfig(t *testing.T) {
+	tests := []struct {
+		name                   string
+		config                 *Config
+		idle, perHost          int
+		idleTimeout, handshake time.Duration
+	}{
+		{"default", &Config{Timeout: time.Second}, 100, 10, 90 * time.Second, 10 * time.Second},
+		{"custom", &Config{
+			Timeout:             time.Second,
+			MaxIdleConns:        500,
+			MaxIdleConnsPerHost: 200,
+			IdleConnTimeout:     30 * time.Second,
+			TLSHandshakeTimeout: 3 * time.Second,
+		}, 500, 200, 30 * time.Second, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		transport := NewClient(tt.config, nil).httpClient.Transport.(*http.Transport)
+		if transport.MaxIdleConns != tt.idle || transport.MaxIdleConnsPerHost != tt.perHost ||
+			transport.IdleConnTimeout != tt.idleTimeout || transport.TLSHandshakeTimeout != tt.handshake {
+			t.Errorf("%s: got MaxIdleConns=%d MaxIdleConnsPerHost=%d IdleConnTimeout=%v TLSHandshakeTimeout=%v",
+				tt.name, transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout, transport.TLSHandshakeTimeout)
+		}
+	}
+}
+
+// TestRetryIdempotentOnly 测试默认只重试幂等请求，POST需显式开启RetryUnsafeMethods
+func TestRetryIdempotentOnly(t *testing.T) {
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		unsafe    bool
+		keyHeader string
+		do        func(c *Client) (*Response, error)
+		want      int32
+	}{
+		{"post", false, "", func(c *Client) (*Response, error) { return c.Post(server.URL, []byte("x"), nil) }, 1},
+		{"patch", false, "", func(c *Client) (*Response, error) { return c.Patch(server.URL, []byte("x"), nil) }, 1},
+		{"get", false, "", func(c *Client) (*Response, error) { return c.Get(server.URL, nil, nil) }, 3},
+		{"put", false, "", func(c *Client) (*Response, error) { return c.Put(server.URL, []byte("x"), nil) }, 3},
+		{"delete", false, "", func(c *Client) (*Response, error) { return c.Delete(server.URL, nil, nil) }, 3},
+		{"post opt-in", true, "", func(c *Client) (*Response, error) { return c.Post(server.URL, []byte("x"), nil) }, 3},
+		{"post idempotency key", false, "Idempotency-Key", func(c *Client) (*Response, error) { return c.Post(server.URL, []byte("x"), nil) }, 3},
+	}
+	for _, tt := range tests {
+		count.Store(0)
+		client := NewClient(&Config{Timeout: time.Second, RetryCount: 2, RetryUnsafeMethods: tt.unsafe, IdempotencyKeyHeader: tt.keyHeader}, nil)
+		recordSleeps(client)
+		resp, err := tt.do(client)
+		if err != nil || resp.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("%s: unexpected result %v %v", tt.name, resp, err)
+		}
+		if got := count.Load(); got != tt.want {
+			t.Errorf("%s: server hit %d times, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestValidationError 测试422响应解析为字段错误
+func TestValidationError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		if r.URL.Path == "/custom" {
+			w.Write([]byte(`{"detail":[{"loc":"email","msg":"invalid"}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":{"name":["is required"],"age":["must be positive","too small"]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	var result MockResponse
+	err := client.PostJSON(server.URL, map[string]string{}, nil, &result)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	if got := validationErr.Fields["name"]; len(got) != 1 || got[0] != "is required" {
+		t.Errorf("Unexpected name errors: %v", got)
+	}
+	if got := validationErr.Fields["age"]; len(got) != 2 {
+		t.Errorf("Unexpected age errors: %v", got)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected wrapped HTTPError with status 422, got %v", err)
+	}
+
+	// 自定义解析函数
+	client = NewClient(&Config{
+		Timeout: 5 * time.Second,
+		ValidationErrorDecoder: func(body []byte) (map[string][]string, error) {
+			var payload struct {
+				Detail []struct {
+					Loc string `json:"loc"`
+					Msg string `json:"msg"`
+				} `json:"detail"`
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				return nil, err
+			}
+			fields := make(map[string][]string)
+			for _, d := range payload.Detail {
+				fields[d.Loc] = append(fields[d.Loc], d.Msg)
+			}
+			return fields, nil
+		},
+	}, nil)
+	err = client.PostJSON(server.URL+"/custom", map[string]string{}, nil, &result)
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	if got := validationErr.Fields["email"]; len(got) != 1 || got[0] != "invalid" {
+		t.Errorf("Unexpected email errors: %v", got)
+	}
+
+	// 无法解析出字段时退回HTTPError
+	err = NewClient(&Config{Timeout: 5 * time.Second}, nil).PostJSON(server.URL+"/custom", map[string]string{}, nil, &result)
+	if errors.As(err, &validationErr) {
+		t.Errorf("Expected plain HTTPError, got %v", err)
+	}
+	if !errors.As(err, &httpErr) {
+		t.Errorf("Expected HTTPError, got %v", err)
+	}
+}
+
+// TestGetOK 测试GetOK/PostOK对非2xx状态码返回错误，而Get/Post不返回
+func TestGetOK(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+
+	resp, err := client.Get(server.URL+"/missing", nil, nil)
+	if err != nil {
+		t.Fatalf("Get should not fail on 404: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+
+	_, err = client.GetOK(server.URL+"/missing", nil, nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusNotFound || string(httpErr.Body) != "not found" {
+		t.Errorf("Unexpected HTTPError: %d %s", httpErr.StatusCode, httpErr.Body)
+	}
+
+	_, err = client.PostOK(server.URL+"/missing", []byte("{}"), nil)
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected HTTPError with status 404 from PostOK, got %v", err)
+	}
+
+	resp, err = client.GetOK(server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("GetOK failed on 201: %v", err)
+	}
+	if string(resp.Body) != "ok" {
+		t.Errorf("Expected body ok, got %s", resp.Body)
+	}
+}
+
+// TestPostJSONContentType 测试PostJSON显式设置Content-Type，调用方指定的类型优先
+func TestPostJSONContentType(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewEncoder(w).Encode(MockResponse{Message: "ok", Code: 200})
+	}))
+	defer server.Close()
+
+	// 全局请求头中的Content-Type不影响JSON请求
+	client := NewClient(&Config{
+		Timeout: 5 * time.Second,
+		Headers: map[string]string{"Content-Type": "text/plain"},
+	}, nil)
+	var result MockResponse
+	headers := map[string]string{"X-Request-Source": "test"}
+	if err := client.PostJSON(server.URL, map[string]string{"a": "b"}, headers, &result); err != nil {
+		t.Fatalf("PostJSON failed: %v", err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", contentType)
+	}
+	if len(headers) != 1 {
+		t.Errorf("Caller headers should not be modified, got %v", headers)
+	}
+
+	for _, want := range []string{"application/json; charset=utf-8", "application/vnd.api+json"} {
+		err := client.PostJSON(server.URL, map[string]string{"a": "b"}, map[string]string{"content-type": want}, &result)
+		if err != nil {
+			t.Fatalf("PostJSON failed: %v", err)
+		}
+		if contentType != want {
+			t.Errorf("Expected Content-Type %q, got %q", want, contentType)
+		}
+	}
+}
+
+// TestJSONResp 测试GetJSONResp/PostJSONResp解析响应的同时返回响应头
+func TestJSONResp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<https://api.example.com/items?page=2>; rel="next"`)
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		if r.URL.Path == "/limited" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(MockResponse{Message: r.Method, Code: 200})
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+
+	var result MockResponse
+	resp, err := client.GetJSONResp(server.URL, nil, nil, &result)
+	if err != nil {
+		t.Fatalf("GetJSONResp failed: %v", err)
+	}
+	if result.Message != "GET" || result.Code != 200 {
+		t.Errorf("Unexpected decoded result: %+v", result)
+	}
+	if got := resp.Headers.Get("Link"); !strings.Contains(got, `rel="next"`) {
+		t.Errorf("Expected Link header, got %q", got)
+	}
+
+	resp, err = client.PostJSONResp(server.URL, map[string]string{"a": "b"}, nil, &result)
+	if err != nil {
+		t.Fatalf("PostJSONResp failed: %v", err)
+	}
+	if result.Message != "POST" {
+		t.Errorf("Unexpected decoded result: %+v", result)
+	}
+	if got := resp.Headers.Get("X-RateLimit-Remaining"); got != "42" {
+		t.Errorf("Expected X-RateLimit-Remaining 42, got %q", got)
+	}
+
+	// 状态码非200时同时返回响应与错误
+	resp, err = client.GetJSONResp(server.URL+"/limited", nil, nil, &result)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected HTTPError with status 429, got %v", err)
+	}
+	if resp == nil || resp.Headers.Get("X-RateLimit-Remaining") != "42" {
+		t.Errorf("Expected response alongside error, got %+v", resp)
+	}
+}
+
 // TestTimeout 测试超时设置
 func TestTimeout(t *testing.T) {
 	// 创建测试服务器，永远不响应
@@ -506,13 +1114,12 @@ func TestHTTPSRequest(t *testing.T) {
 	// 使用服务器的客户端，它已经配置了正确的证书验证
 	httpClient := server.Client()
 
-	// 创建我们的客户端，复用服务器的Transport（包含正确的证书验证配置）
-	client := NewClient(&Config{
+	// 创建我们的客户端，复用服务器的http.Client（包含正确的证书验证配置）
+	client := NewClientWithHTTPClient(&Config{
 		Timeout: 30 * time.Second,
-	}, nil)
-	// 使用服务器客户端的Transport来确保证书验证正确
-	if transport, ok := httpClient.Transport.(*http.Transport); ok {
-		client.httpClient.Transport = transport
+	}, httpClient)
+	if client.httpClient.Transport != httpClient.Transport {
+		t.Fatal("Expected the provided http.Client's Transport to be preserved")
 	}
 
 	// 执行HTTPS请求
@@ -700,23 +1307,23 @@ func TestProxyPoolRandom(t *testing.T) {
 		Timeout:           5 * time.Second,
 	}, nil)
 
-	// 执行多次请求
-	for i := 0; i < 10; i++ {
+	// 执行多次请求，直到所有代理都被使用过（最多100次，避免随机性导致的偶发失败）
+	proxyUsage := make(map[string]bool)
+	for i := 0; i < 100 && len(proxyUsage) < len(proxies); i++ {
 		var response MockResponse
 		err := client.GetJSON(targetServer.URL+"/test", nil, nil, &response)
 		if err != nil {
 			t.Fatalf("Request with random proxy failed: %v", err)
 		}
+
+		mu.Lock()
+		for _, id := range proxyIdentifiers {
+			proxyUsage[id] = true
+		}
+		mu.Unlock()
 	}
 
 	// 验证所有代理至少被使用一次
-	mu.Lock()
-	defer mu.Unlock()
-
-	proxyUsage := make(map[string]bool)
-	for _, id := range proxyIdentifiers {
-		proxyUsage[id] = true
-	}
 
 	for _, p := range proxies {
 		if !proxyUsage[p.ID] {
@@ -724,3 +1331,638 @@ func TestProxyPoolRandom(t *testing.T) {
 		}
 	}
 }
+
+// TestProxyPoolConcurrent 测试并发请求下轮询策略的代理选择是均匀且线程安全的
+func TestProxyPoolConcurrent(t *testing.T) {
+	proxyURLs := []string{"http://127.0.0.1:8001", "http://127.0.0.1:8002", "http://127.0.0.1:8003"}
+	client := NewClient(&Config{ProxyURLs: proxyURLs, ProxyPoolStrategy: "round-robin"}, nil)
+
+	// 代理池应接入Transport.Proxy，而不是每次请求复制Transport
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatal("Expected proxy pool to be wired into Transport.Proxy")
+	}
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			proxyURL, err := transport.Proxy(req)
+			if err != nil {
+				t.Errorf("Proxy() error: %v", err)
+				return
+			}
+			mu.Lock()
+			counts[proxyURL.String()]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, proxyURL := range proxyURLs {
+		if counts[proxyURL] != 100 {
+			t.Errorf("Expected proxy %s to be selected 100 times, got %d", proxyURL, counts[proxyURL])
+		}
+	}
+}
+
+// mockTransport 模拟的RoundTripper，按顺序返回预设的状态码
+type mockTransport struct {
+	mu       sync.Mutex
+	calls    int
+	statuses []int
+	body     string
+	requests []*http.Request
+	headers  []http.Header
+}
+
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := m.statuses[len(m.statuses)-1]
+	if m.calls < len(m.statuses) {
+		status = m.statuses[m.calls]
+	}
+	m.calls++
+	m.requests = append(m.requests, req)
+	m.headers = append(m.headers, req.Header.Clone())
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(m.body)),
+		Request:    req,
+	}, nil
+}
+
+// TestClientWithMockTransport 测试注入mock传输层返回预设响应
+func TestClientWithMockTransport(t *testing.T) {
+	rt := &mockTransport{statuses: []int{http.StatusOK}, body: `{"message":"mocked","code":0}`}
+	client := NewClientWithTransport(&Config{
+		Timeout: 5 * time.Second,
+		Headers: map[string]string{"X-App": "demo"},
+	}, rt)
+
+	var result MockResponse
+	if err := client.GetJSON("http://mock.local/test", nil, nil, &result); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+	if result.Message != "mocked" {
+		t.Errorf("Expected message 'mocked', got '%s'", result.Message)
+	}
+	if rt.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", rt.calls)
+	}
+	// 全局请求头依然生效
+	if got := rt.requests[0].Header.Get("X-App"); got != "demo" {
+		t.Errorf("Expected header X-App=demo, got '%s'", got)
+	}
+}
+
+// TestRetryWithMockTransport 测试持续返回500时的重试次数
+func TestRetryWithMockTransport(t *testing.T) {
+	rt := &mockTransport{statuses: []int{http.StatusInternalServerError}, body: `{"message":"error"}`}
+	client := NewClientWithTransport(&Config{
+		Timeout:    5 * time.Second,
+		RetryCount: 2,
+		RetryDelay: time.Millisecond,
+	}, rt)
+
+	resp, err := client.Get("http://mock.local/retry", nil, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", resp.StatusCode)
+	}
+	// 首次请求 + 2次重试
+	if rt.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", rt.calls)
+	}
+}
+
+// captureLogger 记录日志内容的Logger
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) WriteLog(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(msg, keysAndValues...))
+}
+
+func (l *captureLogger) FatalLog(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.WriteLog(ctx, msg, keysAndValues...)
+}
+
+// TestRetryLogger 测试重试日志通过Config.Logger输出
+func TestRetryLogger(t *testing.T) {
+	logger := &captureLogger{}
+	rt := &mockTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, body: `{}`}
+	client := NewClientWithTransport(&Config{
+		Timeout:    5 * time.Second,
+		RetryCount: 1,
+		Logger:     logger,
+	}, rt)
+
+	if _, err := client.Get("http://mock.local/log", nil, nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if len(logger.lines) != 1 {
+		t.Fatalf("Expected 1 log line, got %d: %v", len(logger.lines), logger.lines)
+	}
+	if !strings.Contains(logger.lines[0], "Retrying request to http://mock.local/log, attempt 1/1") {
+		t.Errorf("Unexpected log line: %s", logger.lines[0])
+	}
+}
+
+// TestRequestLogging 测试开启LogRequests后每次请求输出一行日志，敏感请求头脱敏
+func TestRequestLogging(t *testing.T) {
+	logger := &captureLogger{}
+	rt := &mockTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, body: `{}`}
+	client := NewClientWithTransport(&Config{
+		Timeout:     5 * time.Second,
+		RetryCount:  1,
+		Logger:      logger,
+		LogRequests: true,
+	}, rt)
+
+	headers := map[string]string{"Authorization": "Bearer secret-token", "Cookie": "session=secret-cookie", "X-Trace": "abc"}
+	if _, err := client.Get("http://mock.local/log", nil, headers); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	var requestLines []string
+	for _, line := range logger.lines {
+		if strings.HasPrefix(line, "HTTP ") {
+			requestLines = append(requestLines, line)
+		}
+	}
+	if len(requestLines) != 2 {
+		t.Fatalf("Expected 2 request log lines, got %v", logger.lines)
+	}
+	for i, status := range []string{"status=503", "status=200"} {
+		line := requestLines[i]
+		if !strings.HasPrefix(line, "HTTP GET http://mock.local/log ") || !strings.Contains(line, status) || !strings.Contains(line, "elapsed=") {
+			t.Errorf("Unexpected log line: %s", line)
+		}
+		if strings.Contains(line, "secret") {
+			t.Errorf("Expected sensitive headers to be redacted: %s", line)
+		}
+		if !strings.Contains(line, "Authorization=[REDACTED]") || !strings.Contains(line, "X-Trace=abc") {
+			t.Errorf("Unexpected headers in log line: %s", line)
+		}
+	}
+
+	// 未设置Logger时不输出也不panic
+	client = NewClientWithTransport(&Config{Timeout: 5 * time.Second, LogRequests: true}, rt)
+	if _, err := client.Get("http://mock.local/log", nil, nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+}
+
+// TestIdempotencyKey 测试重试时幂等键保持不变
+func TestIdempotencyKey(t *testing.T) {
+	rt := &mockTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}, body: `{}`}
+	client := NewClientWithTransport(&Config{
+		Timeout:              5 * time.Second,
+		RetryCount:           2,
+		IdempotencyKeyHeader: "Idempotency-Key",
+	}, rt)
+
+	if _, err := client.Post("http://mock.local/orders", []byte(`{"id":1}`), nil); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if rt.calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", rt.calls)
+	}
+	key := rt.headers[0].Get("Idempotency-Key")
+	if key == "" {
+		t.Fatal("Expected Idempotency-Key header")
+	}
+	for i, h := range rt.headers {
+		if h.Get("Idempotency-Key") != key {
+			t.Errorf("Attempt %d: expected key %s, got %s", i, key, h.Get("Idempotency-Key"))
+		}
+	}
+
+	// 每个逻辑请求使用不同的幂等键
+	if _, err := client.Post("http://mock.local/orders", []byte(`{"id":2}`), nil); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if rt.headers[len(rt.headers)-1].Get("Idempotency-Key") == key {
+		t.Error("Expected a new key for a new request")
+	}
+
+	// GET请求不携带幂等键
+	if _, err := client.Get("http://mock.local/orders", nil, nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if rt.headers[len(rt.headers)-1].Get("Idempotency-Key") != "" {
+		t.Error("Expected no key for GET request")
+	}
+}
+
+// TestUserAgentAndHeaderPriority 测试User-Agent与请求头的覆盖顺序
+func TestUserAgentAndHeaderPriority(t *testing.T) {
+	rt := &mockTransport{statuses: []int{http.StatusOK}, body: `{}`}
+	client := NewClientWithTransport(&Config{
+		Timeout:   5 * time.Second,
+		UserAgent: "go-vendor-client/1.0",
+		Headers:   map[string]string{"X-Env": "prod", "X-App": "demo"},
+	}, rt)
+
+	// 使用配置的User-Agent
+	if _, err := client.Get("http://mock.local/ua", nil, nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if ua := rt.headers[0].Get("User-Agent"); ua != "go-vendor-client/1.0" {
+		t.Errorf("Expected configured User-Agent, got %s", ua)
+	}
+
+	// 单次请求的请求头优先于配置
+	if _, err := client.Get("http://mock.local/ua", nil, map[string]string{
+		"User-Agent": "custom-agent",
+		"X-Env":      "test",
+	}); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	h := rt.headers[1]
+	if ua := h.Get("User-Agent"); ua != "custom-agent" {
+		t.Errorf("Expected per-call User-Agent, got %s", ua)
+	}
+	if env := h.Get("X-Env"); env != "test" {
+		t.Errorf("Expected per-call X-Env=test, got %s", env)
+	}
+	if app := h.Get("X-App"); app != "demo" {
+		t.Errorf("Expected config X-App=demo, got %s", app)
+	}
+}
+
+// TestGetCtxCancel 测试取消调用方的ctx会中止进行中的请求及等待中的重试
+func TestGetCtxCancel(t *testing.T) {
+	var requests sync.WaitGroup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// 阻塞直到客户端断开
+		requests.Done()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		Timeout:    10 * time.Second,
+		RetryCount: 3,
+		RetryDelay: 10 * time.Second,
+	}, nil)
+
+	// 请求进行中取消
+	requests.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		requests.Wait()
+		cancel()
+	}()
+	start := time.Now()
+	_, err := client.GetCtx(ctx, server.URL+"/slow", nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected prompt return after cancel, took %v", elapsed)
+	}
+
+	// 等待重试期间取消
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/unavailable", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start = time.Now()
+	resp, err := client.DoCtx(ctx, req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected last 503 response, got %+v", resp)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected prompt return after deadline, took %v", elapsed)
+	}
+}
+
+// gatedReader 生成指定长度的数据，已生成的数据比服务端已接收的多出limit字节时等待服务端读取
+// 客户端若先将请求体整体读入内存再发送，读取会因服务端始终未收到数据而失败
+type gatedReader struct {
+	remaining int64
+	generated int64
+	received  *atomic.Int64
+	limit     int64
+}
+
+func (r *gatedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for r.generated-r.received.Load() > r.limit {
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("upload is not streamed: generated %d bytes, server received %d", r.generated, r.received.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	for i := range p {
+		p[i] = byte(r.generated + int64(i))
+	}
+	r.generated += int64(len(p))
+	r.remaining -= int64(len(p))
+	return len(p), nil
+}
+
+// TestUploadFileStreaming 测试大文件上传以流式发送，不在客户端整体缓存
+func TestUploadFileStreaming(t *testing.T) {
+	const size = 64 << 20
+	var received atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fields := map[string]int64{}
+		buf := make([]byte, 32<<10)
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for {
+				n, err := part.Read(buf)
+				received.Add(int64(n))
+				fields[part.FormName()] += int64(n)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(fields)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 30 * time.Second}, nil)
+	file := FileInfo{
+		FieldName: "file",
+		FileName:  "large.bin",
+		Reader:    &gatedReader{remaining: size, received: &received, limit: 8 << 20},
+	}
+	resp, err := client.UploadFile(server.URL, file, map[string]string{"description": "large"}, nil)
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	var fields map[string]int64
+	if err := json.Unmarshal(resp.Body, &fields); err != nil {
+		t.Fatalf("Failed to parse response %q: %v", resp.Body, err)
+	}
+	if fields["file"] != size {
+		t.Errorf("Expected %d file bytes, server received %d", size, fields["file"])
+	}
+	if fields["description"] != int64(len("large")) {
+		t.Errorf("Expected description field, got %v", fields)
+	}
+
+	// FilePath指定的文件同样流式上传
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), 1<<20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.UploadFiles(server.URL, []FileInfo{{FieldName: "disk", FileName: "data.bin", FilePath: path}}, nil, nil)
+	if err != nil {
+		t.Fatalf("UploadFiles failed: %v", err)
+	}
+	fields = nil
+	if err := json.Unmarshal(resp.Body, &fields); err != nil {
+		t.Fatalf("Failed to parse response %q: %v", resp.Body, err)
+	}
+	if fields["disk"] != 1<<20 {
+		t.Errorf("Expected %d file bytes, server received %d", 1<<20, fields["disk"])
+	}
+}
+
+// TestUploadFileProgress 测试上传进度回调单调递增并以文件大小结束
+func TestUploadFileProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+	client := NewClient(nil, nil)
+
+	const size = 1<<20 + 123
+	content := bytes.Repeat([]byte("p"), size)
+	for _, tt := range []struct {
+		size      int64
+		wantTotal int64
+	}{
+		{size, size},
+		{0, -1},
+	} {
+		var sent []int64
+		file := FileInfo{
+			FieldName: "file",
+			FileName:  "progress.bin",
+			Reader:    bytes.NewReader(content),
+			Size:      tt.size,
+			ProgressFunc: func(bytesSent, totalBytes int64) {
+				if totalBytes != tt.wantTotal {
+					t.Errorf("Expected total %d, got %d", tt.wantTotal, totalBytes)
+				}
+				sent = append(sent, bytesSent)
+			},
+		}
+		if _, err := client.UploadFile(server.URL, file, nil, nil); err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+
+		if len(sent) < 2 || len(sent) > size/progressInterval+1 {
+			t.Fatalf("Expected periodic progress callbacks, got %d", len(sent))
+		}
+		for i := 1; i < len(sent); i++ {
+			if sent[i] <= sent[i-1] {
+				t.Fatalf("Progress not increasing: %v", sent)
+			}
+		}
+		if last := sent[len(sent)-1]; last != size {
+			t.Errorf("Expected final progress %d, got %d", size, last)
+		}
+	}
+}
+
+// TestUploadFileContentType 测试文件部分的Content-Type按扩展名推断、按内容检测及显式指定
+func TestUploadFileContentType(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			t.Errorf("Failed to parse multipart form: %v", err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for field, headers := range r.MultipartForm.File {
+			received[field] = headers[0].Header.Get("Content-Type")
+		}
+	}))
+	defer server.Close()
+	client := NewClient(nil, nil)
+
+	pngData := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+	files := []FileInfo{
+		{FieldName: "by_ext", FileName: "image.png", Reader: strings.NewReader("not really a png")},
+		{FieldName: "pdf", FileName: "doc.pdf", Reader: strings.NewReader("%PDF-1.4")},
+		{FieldName: "sniffed", FileName: "upload", Reader: bytes.NewReader(pngData)},
+		{FieldName: "unknown", FileName: "data", Reader: bytes.NewReader([]byte{0, 1, 2, 3})},
+		{FieldName: "explicit", FileName: "image.png", Reader: strings.NewReader("x"), ContentType: "application/x-custom"},
+	}
+	if _, err := client.UploadFiles(server.URL, files, nil, nil); err != nil {
+		t.Fatalf("UploadFiles failed: %v", err)
+	}
+
+	want := map[string]string{
+		"by_ext":   "image/png",
+		"pdf":      "application/pdf",
+		"sniffed":  "image/png",
+		"unknown":  "application/octet-stream",
+		"explicit": "application/x-custom",
+	}
+	for field, contentType := range want {
+		if received[field] != contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", field, received[field], contentType)
+		}
+	}
+}
+
+// TestConfigAuth 测试Config中的Basic认证与Bearer令牌，单次请求的Authorization优先
+func TestConfigAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		headers map[string]string
+		want    string
+	}{
+		{"none", Config{}, nil, ""},
+		{"basic", Config{BasicAuthUser: "alice", BasicAuthPass: "s3cret"}, nil, "Basic YWxpY2U6czNjcmV0"},
+		{"bearer", Config{BearerToken: "token-1"}, nil, "Bearer token-1"},
+		{"bearer over basic", Config{BearerToken: "token-1", BasicAuthUser: "alice", BasicAuthPass: "s3cret"}, nil, "Bearer token-1"},
+		{"per-request override", Config{BearerToken: "token-1"}, map[string]string{"Authorization": "Bearer per-call"}, "Bearer per-call"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &mockTransport{statuses: []int{http.StatusOK}, body: `{}`}
+			config := tt.config
+			config.Timeout = 5 * time.Second
+			client := NewClientWithTransport(&config, rt)
+
+			if _, err := client.Get("http://mock.local/auth", nil, tt.headers); err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got := rt.headers[0].Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNoRetryOnContextCancel 测试ctx取消或超时引起的错误不重试，立即返回
+func TestNoRetryOnContextCancel(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		Timeout:    10 * time.Second,
+		RetryCount: 3,
+		RetryDelay: time.Second,
+	}, nil)
+	sleeps := recordSleeps(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetCtx(ctx, server.URL, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if strings.Contains(err.Error(), "retry aborted") || len(*sleeps) != 0 || attempts.Load() != 1 {
+		t.Errorf("Expected no retry, got err=%v sleeps=%v attempts=%d", err, *sleeps, attempts.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected prompt return, took %v", elapsed)
+	}
+
+	for _, err := range []error{
+		context.Canceled,
+		&url.Error{Op: "Get", URL: "http://example.com", Err: context.Canceled},
+		fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+	} {
+		if isRetryableError(err) {
+			t.Errorf("isRetryableError(%v) = true, want false", err)
+		}
+	}
+}
+
+// TestMaxResponseBytes 测试响应体大小限制
+func TestMaxResponseBytes(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		w.Write(bytes.Repeat([]byte("a"), size))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: time.Second, RetryCount: 2, MaxResponseBytes: 1024}, nil)
+	recordSleeps(client)
+
+	resp, err := client.Get(server.URL, map[string]string{"size": "1024"}, nil)
+	if err != nil {
+		t.Fatalf("Get under limit failed: %v", err)
+	}
+	if len(resp.Body) != 1024 {
+		t.Errorf("Expected 1024 bytes, got %d", len(resp.Body))
+	}
+
+	calls.Store(0)
+	_, err = client.Get(server.URL, map[string]string{"size": "1025"}, nil)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Oversized response should not be retried, got %d calls", calls.Load())
+	}
+}
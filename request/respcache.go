@@ -0,0 +1,87 @@
+package request
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// responseCacheKeyPrefix GET响应缓存键的前缀
+const responseCacheKeyPrefix = "request:get:"
+
+// cachedResponse 写入Config.Cache的GET响应
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers"`
+	Body       []byte      `json:"body"`
+}
+
+// responseCacheKey 计算GET响应的缓存键，由完整URL、全局及单次请求头和认证配置共同决定
+// 请求头和认证信息可能包含令牌，取哈希后作为键，避免明文写入缓存
+func (c *Client) responseCacheKey(fullURL string, headers map[string]string) string {
+	merged := make(map[string]string, len(c.config.Headers)+len(headers))
+	for key, value := range c.config.Headers {
+		merged[http.CanonicalHeaderKey(key)] = value
+	}
+	for key, value := range headers {
+		merged[http.CanonicalHeaderKey(key)] = value
+	}
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(fullURL))
+	for _, key := range keys {
+		h.Write([]byte("\n" + key + ":" + merged[key]))
+	}
+	h.Write([]byte("\nbearer:" + c.config.BearerToken + "\nbasic:" + c.config.BasicAuthUser + ":" + c.config.BasicAuthPass))
+	return responseCacheKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// noStore 判断Cache-Control是否包含no-store
+func noStore(cacheControl string) bool {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+// requestNoStore 判断单次请求头中是否设置了Cache-Control: no-store
+func requestNoStore(headers map[string]string) bool {
+	for key, value := range headers {
+		if strings.EqualFold(key, "Cache-Control") && noStore(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// getCachedResponse 从Config.Cache读取GET响应，未命中或读取失败时返回nil
+// 命中的响应Attempts为0，表示未实际发送请求
+func (c *Client) getCachedResponse(ctx context.Context, key string) *Response {
+	var cached cachedResponse
+	if err := c.config.Cache.Get(ctx, key, &cached); err != nil {
+		return nil
+	}
+	return &Response{StatusCode: cached.StatusCode, Headers: cached.Headers, Body: cached.Body}
+}
+
+// storeResponse 将200响应写入Config.Cache，响应头带有Cache-Control: no-store时不缓存
+// 写入失败只记录日志，不影响本次请求的结果
+func (c *Client) storeResponse(ctx context.Context, key string, resp *Response) {
+	if resp.StatusCode != http.StatusOK || noStore(resp.Headers.Get("Cache-Control")) {
+		return
+	}
+	cached := cachedResponse{StatusCode: resp.StatusCode, Headers: resp.Headers, Body: resp.Body}
+	if err := c.config.Cache.Set(ctx, key, cached, c.config.CacheTTL); err != nil {
+		c.logf(ctx, "Failed to cache response: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/cache"
+)
+
+// TestResponseCache 测试TTL内相同的GET请求只访问一次服务端，请求头不同或no-store时不使用缓存
+func TestResponseCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private, no-store")
+		}
+		w.Write([]byte(`{"message":"` + r.Header.Get("X-Tenant") + `","code":200}`))
+	}))
+	defer server.Close()
+
+	store := cache.NewMemoryCache()
+	defer store.Close()
+	client := NewClient(&Config{Timeout: 5 * time.Second, Cache: store, CacheTTL: time.Minute}, nil)
+
+	var first, second MockResponse
+	if err := client.GetJSON(server.URL, map[string]string{"id": "1"}, map[string]string{"X-Tenant": "a"}, &first); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if err := client.GetJSON(server.URL, map[string]string{"id": "1"}, map[string]string{"X-Tenant": "a"}, &second); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected 1 server call, got %d", n)
+	}
+	if second != first || second.Message != "a" {
+		t.Errorf("Expected cached response %+v, got %+v", first, second)
+	}
+
+	// 请求头不同时缓存键不同
+	resp, err := client.Get(server.URL+"?id=1", nil, map[string]string{"X-Tenant": "b"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Attempts != 1 {
+		t.Errorf("Expected a fresh request, got %d attempts", resp.Attempts)
+	}
+
+	// 请求带有no-store时不读缓存
+	if _, err := client.Get(server.URL, map[string]string{"id": "1"}, map[string]string{"X-Tenant": "a", "Cache-Control": "no-store"}); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	// 响应带有no-store时不写缓存
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(server.URL+"/private", nil, nil); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 5 {
+		t.Errorf("Expected 5 server calls, got %d", n)
+	}
+}
@@ -0,0 +1,213 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// streamBody 流式响应体，关闭时释放请求的context
+type streamBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close 关闭响应体并释放context
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// GetStream 执行GET请求并返回未读取的响应体，适用于下载大文件等场景，调用方可边读边写入磁盘
+// 返回的body与resp.Body相同，调用方负责关闭，否则连接和context无法释放
+// Config.Timeout只限制收到响应头之前的时间，读取响应体不受其限制，可通过Config.Context取消；
+// 在拿到响应之前，网络错误与可重试状态码按配置重试，响应体交给调用方后不再重试
+// 非2xx状态码不视为错误，由调用方检查resp.StatusCode
+func (c *Client) GetStream(url string, params, headers map[string]string) (io.ReadCloser, *http.Response, error) {
+	return c.getStream(c.config.Context, url, params, headers)
+}
+
+// getStream GetStream的实现，请求及重试等待使用ctx
+func (c *Client) getStream(ctx context.Context, url string, params, headers map[string]string) (io.ReadCloser, *http.Response, error) {
+	fullURL, err := appendQuery(url, valuesFromMap(params))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 复制客户端并去掉整体超时，否则http.Client.Timeout会在读取响应体期间中断下载
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			c.logf(ctx, "Retrying request to %s, attempt %d/%d", fullURL, attempt, c.config.RetryCount)
+		}
+		req, cancel, err := c.newStreamRequest(ctx, fullURL, headers)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 与doWithRetry相同，限流先于熔断器检查且不计入熔断统计
+		if err := c.waitRateLimit(req); err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		if err := c.allowRequest(req); err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		resp, err := c.doStream(&streamClient, req, cancel)
+		c.recordResult(req, resp, err)
+		if err != nil {
+			if isRetryableError(err) && attempt < c.config.RetryCount {
+				if waitErr := c.waitRetry(ctx, attempt+1); waitErr != nil {
+					return nil, nil, fmt.Errorf("retry aborted: %w", waitErr)
+				}
+				continue
+			}
+			return nil, nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		if retryableStatusCodes[resp.StatusCode] && attempt < c.config.RetryCount {
+			DrainBody(resp)
+			cancel()
+			if waitErr := c.waitRetry(ctx, attempt+1); waitErr != nil {
+				return nil, nil, fmt.Errorf("retry aborted: %w", waitErr)
+			}
+			continue
+		}
+
+		body := &streamBody{ReadCloser: resp.Body, cancel: cancel}
+		resp.Body = body
+		return body, resp, nil
+	}
+}
+
+// newStreamRequest 创建一次流式请求，返回的cancel用于中止该请求
+func (c *Client) newStreamRequest(ctx context.Context, fullURL string, headers map[string]string) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	c.setRequestHeaders(req)
+	return req, cancel, nil
+}
+
+// doStream 执行一次流式请求，Config.Timeout内未收到响应头时调用cancel取消请求
+// 失败时已调用cancel；成功时cancel需在响应体关闭后由调用方调用
+func (c *Client) doStream(client *http.Client, req *http.Request, cancel context.CancelFunc) (*http.Response, error) {
+	var timer *time.Timer
+	if c.config.Timeout > 0 {
+		timer = time.AfterFunc(c.config.Timeout, cancel)
+	}
+	resp, err := client.Do(req)
+	// 定时器已触发说明响应头超时，即使拿到了响应，响应体也已无法读取
+	if timer != nil && !timer.Stop() && err == nil {
+		resp.Body.Close()
+		err = fmt.Errorf("timeout awaiting response headers: %w", context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetJSONStream 执行GET请求，逐个解析JSON数组响应中的元素，响应体不会整体读入内存，适用于超大数组
+// elem对每个元素调用一次，需通过decoder.Decode恰好读取一个元素；返回错误时停止解析并返回该错误
+// 响应状态码不是200时返回*HTTPError；重试与超时规则同GetStream
+func (c *Client) GetJSONStream(ctx context.Context, url string, params, headers map[string]string, elem func(decoder *json.Decoder) error) error {
+	body, resp, err := c.getStream(ctx, url, params, headers)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		parsed, err := c.parseResponse(resp)
+		if err != nil {
+			return err
+		}
+		return c.statusError(parsed)
+	}
+
+	decoder := json.NewDecoder(body)
+	if tok, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to read JSON array: %w", err)
+	} else if tok != json.Delim('[') {
+		return fmt.Errorf("expected JSON array, got %v", tok)
+	}
+	for decoder.More() {
+		offset := decoder.InputOffset()
+		if err := elem(decoder); err != nil {
+			return err
+		}
+		// 回调未读取元素时会停在原位置，避免死循环
+		if decoder.InputOffset() == offset {
+			return errors.New("JSON stream callback did not consume an element")
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to read JSON array: %w", err)
+	}
+	return nil
+}
+
+// DownloadFile 执行GET请求并将响应体写入destPath，返回写入的字节数；父目录不存在时自动创建
+// 先写入同目录下的临时文件，完成后再重命名为destPath，失败时删除临时文件，不会留下不完整的目标文件
+// 响应状态码不是2xx时返回*HTTPError；重试与超时规则同GetStream
+func (c *Client) DownloadFile(url, destPath string, headers map[string]string) (int64, error) {
+	body, resp, err := c.getStream(c.config.Context, url, nil, headers)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		parsed, err := c.parseResponse(resp)
+		if err != nil {
+			return 0, err
+		}
+		return 0, c.statusError(parsed)
+	}
+
+	dir := filepath.Dir(destPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(destPath)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	n, err := io.Copy(tmp, body)
+	if err != nil {
+		err = fmt.Errorf("failed to write file: %w", err)
+	} else if err = tmp.Chmod(0644); err != nil {
+		err = fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close file: %w", closeErr)
+	}
+	if err == nil {
+		if renameErr := os.Rename(tmp.Name(), destPath); renameErr != nil {
+			err = fmt.Errorf("failed to rename file: %w", renameErr)
+		}
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return n, err
+	}
+	return n, nil
+}
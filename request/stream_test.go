@@ -0,0 +1,200 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGetStream 测试流式下载几MB的数据并校验摘要，首次请求返回503时重试
+func TestGetStream(t *testing.T) {
+	payload := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(payload)
+	want := sha256.Sum256(payload)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("file") != "a b.bin" {
+			t.Errorf("Expected file=a b.bin, got %s", r.URL.Query().Get("file"))
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		w.WriteHeader(http.StatusOK)
+		// 分块慢速写入，总耗时超过客户端的Timeout
+		chunk := len(payload) / 4
+		for i := 0; i < 4; i++ {
+			w.Write(payload[i*chunk : (i+1)*chunk])
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	// Timeout短于读取完整响应体所需时间，不影响下载
+	client := NewClient(&Config{Timeout: 100 * time.Millisecond, RetryCount: 1, RetryDelay: time.Millisecond}, nil)
+	body, resp, err := client.GetStream(server.URL, map[string]string{"file": "a b.bin"}, nil)
+	if err != nil {
+		t.Fatalf("GetStream failed: %v", err)
+	}
+	defer body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls.Load())
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(h, body)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	if n != int64(len(payload)) {
+		t.Errorf("Expected %d bytes, got %d", len(payload), n)
+	}
+	if [sha256.Size]byte(h.Sum(nil)) != want {
+		t.Error("Checksum mismatch")
+	}
+}
+
+// TestGetStreamHeaderTimeout 测试响应头超时
+func TestGetStreamHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 20 * time.Millisecond}, nil)
+	if _, _, err := client.GetStream(server.URL, nil, nil); err == nil {
+		t.Fatal("Expected timeout error")
+	}
+}
+
+// TestGetJSONStream 测试逐个解析大型JSON数组的元素
+func TestGetJSONStream(t *testing.T) {
+	const count = 200000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			http.Error(w, "boom", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		bw := bufio.NewWriter(w)
+		bw.WriteString("[")
+		for i := 0; i < count; i++ {
+			if i > 0 {
+				bw.WriteString(",")
+			}
+			fmt.Fprintf(bw, `{"message":"item","code":%d}`, i)
+		}
+		bw.WriteString("]")
+		bw.Flush()
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	var n, sum int
+	err := client.GetJSONStream(context.Background(), server.URL, nil, nil, func(decoder *json.Decoder) error {
+		var item MockResponse
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+		if item.Code != n {
+			t.Fatalf("element %d has code %d", n, item.Code)
+		}
+		n++
+		sum += item.Code
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetJSONStream failed: %v", err)
+	}
+	if n != count || sum != count*(count-1)/2 {
+		t.Errorf("Expected %d elements, got %d (sum %d)", count, n, sum)
+	}
+
+	// 非200状态码返回HTTPError
+	err = client.GetJSONStream(context.Background(), server.URL, map[string]string{"fail": "1"}, nil, func(*json.Decoder) error { return nil })
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected HTTPError 400, got %v", err)
+	}
+
+	// 回调未读取元素时返回错误而不是死循环
+	err = client.GetJSONStream(context.Background(), server.URL, nil, nil, func(*json.Decoder) error { return nil })
+	if err == nil {
+		t.Error("Expected error when callback does not consume the element")
+	}
+}
+
+// TestDownloadFile 测试下载到不存在的子目录，以及失败时不留下目标文件和临时文件
+func TestDownloadFile(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 10000))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+		case "/truncated":
+			// 声明的长度大于实际写入的长度，客户端读取时得到unexpected EOF
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write(content[:100])
+		default:
+			w.Write(content)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	dir := t.TempDir()
+
+	dest := filepath.Join(dir, "a", "b", "file.txt")
+	n, err := client.DownloadFile(server.URL+"/file", dest, nil)
+	if err != nil {
+		t.Fatalf("DownloadFile failed: %v", err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("Expected %d bytes written, got %d", len(content), n)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("Failed to read downloaded file: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("Downloaded content mismatch")
+	}
+
+	failDir := filepath.Join(dir, "fail")
+	_, err = client.DownloadFile(server.URL+"/missing", filepath.Join(failDir, "missing.txt"), nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected HTTPError with status 404, got %v", err)
+	}
+
+	if _, err = client.DownloadFile(server.URL+"/truncated", filepath.Join(failDir, "truncated.txt"), nil); err == nil {
+		t.Error("Expected error for truncated body")
+	}
+	entries, _ := os.ReadDir(failDir)
+	if len(entries) != 0 {
+		t.Errorf("Expected no files left after failures, got %d", len(entries))
+	}
+}
@@ -0,0 +1,18 @@
+package request
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// injectTrace 将req上下文中的span写入请求头（如W3C traceparent），使下游服务的span与当前调用链关联
+// 使用otel全局传播器；未通过otel.SetTextMapPropagator设置时（默认不传播任何字段）使用W3C TraceContext
+func injectTrace(req *http.Request) {
+	propagator := otel.GetTextMapPropagator()
+	if len(propagator.Fields()) == 0 {
+		propagator = propagation.TraceContext{}
+	}
+	propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+}
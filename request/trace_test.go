@@ -0,0 +1,49 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TestPropagateTrace 测试请求头携带当前span的traceparent
+func TestPropagateTrace(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	ctx, span := tp.Tracer("request_test").Start(context.Background(), "call")
+	defer span.End()
+
+	client := NewClient(&Config{Timeout: 5 * time.Second, PropagateTrace: true}, nil)
+	if _, err := client.GetCtx(ctx, server.URL, nil, nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	matches := regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-0[0-9a-f]$`).FindStringSubmatch(traceparent)
+	if matches == nil {
+		t.Fatalf("Malformed traceparent: %q", traceparent)
+	}
+	sc := span.SpanContext()
+	if matches[1] != sc.TraceID().String() || matches[2] != sc.SpanID().String() {
+		t.Errorf("traceparent %q does not match span %s/%s", traceparent, sc.TraceID(), sc.SpanID())
+	}
+
+	// 未开启时不携带
+	traceparent = ""
+	client = NewClient(&Config{Timeout: 5 * time.Second}, nil)
+	if _, err := client.GetCtx(ctx, server.URL, nil, nil); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if traceparent != "" {
+		t.Errorf("Expected no traceparent, got %q", traceparent)
+	}
+}
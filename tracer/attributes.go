@@ -0,0 +1,68 @@
+package tracer
+
+import (
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SetAttrs 批量设置span属性，参数为键值对，如 SetAttrs(span, "user.id", 42, "cache.hit", true)
+// 值按类型转换为对应的属性：string、bool、int、int64、float64、time.Duration(字符串)，其他类型使用fmt.Sprint；
+// 键不是字符串或缺少值时忽略该键值对
+func SetAttrs(span trace.Span, keysAndValues ...interface{}) {
+	attrs := make([]attribute.KeyValue, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, toAttribute(key, keysAndValues[i+1]))
+	}
+	span.SetAttributes(attrs...)
+}
+
+// toAttribute 将任意值转换为span属性
+func toAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+// SetDBAttributes 按OpenTelemetry语义约定设置数据库span属性
+// system: 数据库类型，如"mysql"、"redis"；statement: 执行的语句，应使用占位符而非实际参数
+func SetDBAttributes(span trace.Span, system, statement string) {
+	span.SetAttributes(
+		semconv.DBSystemKey.String(system),
+		semconv.DBStatementKey.String(statement),
+	)
+}
+
+// SetHTTPClientAttributes 按OpenTelemetry语义约定设置HTTP客户端span属性
+// status为0表示未收到响应（如连接失败），不设置状态码；4xx、5xx时将span状态设置为Error
+func SetHTTPClientAttributes(span trace.Span, method, url string, status int) {
+	span.SetAttributes(
+		semconv.HTTPMethodKey.String(method),
+		semconv.HTTPURLKey.String(url),
+	)
+	if status == 0 {
+		return
+	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindClient))
+}
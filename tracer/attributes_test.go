@@ -0,0 +1,66 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestAttributeHelpers 测试属性辅助函数设置的键与值
+func TestAttributeHelpers(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("attributes_test")
+
+	_, span := tracer.Start(context.Background(), "db")
+	SetDBAttributes(span, "mysql", "SELECT * FROM users WHERE id = ?")
+	SetAttrs(span, "db.rows_affected", int64(1), "cache.hit", true, "latency", 15*time.Millisecond, "dangling")
+	span.End()
+
+	_, span = tracer.Start(context.Background(), "http")
+	SetHTTPClientAttributes(span, "GET", "https://api.example.com/users", 503)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"db.system":        attribute.StringValue("mysql"),
+		"db.statement":     attribute.StringValue("SELECT * FROM users WHERE id = ?"),
+		"db.rows_affected": attribute.Int64Value(1),
+		"cache.hit":        attribute.BoolValue(true),
+		"latency":          attribute.StringValue("15ms"),
+	}
+	assertAttributes(t, spans[0].Attributes(), want)
+
+	want = map[attribute.Key]attribute.Value{
+		"http.method":      attribute.StringValue("GET"),
+		"http.url":         attribute.StringValue("https://api.example.com/users"),
+		"http.status_code": attribute.IntValue(503),
+	}
+	assertAttributes(t, spans[1].Attributes(), want)
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("Expected error status for 503, got %v", spans[1].Status())
+	}
+}
+
+// assertAttributes 断言attrs恰好包含want中的属性
+func assertAttributes(t *testing.T, attrs []attribute.KeyValue, want map[attribute.Key]attribute.Value) {
+	t.Helper()
+	if len(attrs) != len(want) {
+		t.Errorf("Expected %d attributes, got %v", len(want), attrs)
+	}
+	for _, kv := range attrs {
+		if expected, ok := want[kv.Key]; !ok || expected != kv.Value {
+			t.Errorf("Unexpected attribute %s=%v", kv.Key, kv.Value.Emit())
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lwy110193/go_vendor/ctxkey"
+)
+
+// TestNewSpanWithCtxTraceID 测试NewSpanWithCtx优先读取类型化key，并兼容字符串key
+func TestNewSpanWithCtxTraceID(t *testing.T) {
+	const typedID = "0af7651916cd43dd8448eb211c80319c"
+	const legacyID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	//nolint:staticcheck // 兼容旧代码使用字符串key
+	legacyCtx := context.WithValue(context.Background(), "trace_id", legacyID)
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"typed", ctxkey.WithTraceID(legacyCtx, typedID), typedID},
+		{"legacy", legacyCtx, legacyID},
+	}
+	for _, tt := range tests {
+		ctx, span := NewSpanWithCtx(tt.ctx, "test", "span", "trace_id")
+		span.End()
+		if got, _ := ctxkey.TraceID(ctx); got != tt.want {
+			t.Errorf("%s: ctxkey.TraceID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 字符串key的值不是string时不应panic，而是生成新的traceID
+	//nolint:staticcheck
+	ctx, span := NewSpanWithCtx(context.WithValue(context.Background(), "trace_id", 42), "test", "span", "trace_id")
+	span.End()
+	if got, _ := ctxkey.TraceID(ctx); len(got) != 32 {
+		t.Errorf("generated trace id = %q, want 32 hex chars", got)
+	}
+}
@@ -0,0 +1,101 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	mylog "github.com/lwy110193/go_vendor/log"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// captureLogWriter 记录写入的span名称
+type captureLogWriter struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (w *captureLogWriter) WriteLog(format string, args ...interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.names = append(w.names, fmt.Sprint(args[0]))
+}
+
+func (w *captureLogWriter) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.names)
+}
+
+// tracerProvider 返回InitTracer设置的全局TracerProvider
+func tracerProvider(t *testing.T) *sdktrace.TracerProvider {
+	tp, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	if !ok {
+		t.Fatalf("unexpected tracer provider %T", otel.GetTracerProvider())
+	}
+	return tp
+}
+
+// TestCustomExporterShutdownFlushes 测试InitTracer的清理函数会写出缓冲中剩余的span
+func TestCustomExporterShutdownFlushes(t *testing.T) {
+	writer := &captureLogWriter{}
+	exporter, err := mylog.NewCustomExporter(mylog.WithLogWriter(writer), mylog.WithBufferSize(100))
+	if err != nil {
+		t.Fatalf("NewCustomExporter() error = %v", err)
+	}
+	cleanup := InitTracer("TestCustomExporterShutdownFlushes", exporter)
+
+	for i := 0; i < 5; i++ {
+		_, span := NewTraceSpan(context.Background(), "exporter-test", fmt.Sprintf("span-%d", i))
+		span.End()
+	}
+	if n := writer.len(); n != 0 {
+		t.Fatalf("Expected spans to be buffered before shutdown, got %d written", n)
+	}
+
+	cleanup()
+	if n := writer.len(); n != 5 {
+		t.Fatalf("Expected 5 spans written after shutdown, got %d: %v", n, writer.names)
+	}
+	for i, name := range writer.names {
+		if name != fmt.Sprintf("span-%d", i) {
+			t.Errorf("span %d name = %s", i, name)
+		}
+	}
+
+	// 关闭后导出的span被丢弃
+	exporter.ExportSpans(context.Background(), nil)
+	if n := writer.len(); n != 5 {
+		t.Errorf("Expected no writes after shutdown, got %d", n)
+	}
+}
+
+// TestCustomExporterForceFlush 测试缓冲满时写入及ForceFlush写出剩余span
+func TestCustomExporterForceFlush(t *testing.T) {
+	writer := &captureLogWriter{}
+	exporter, _ := mylog.NewCustomExporter(mylog.WithLogWriter(writer), mylog.WithBufferSize(3))
+	cleanup := InitTracer("TestCustomExporterForceFlush", exporter)
+	defer cleanup()
+
+	tp := tracerProvider(t)
+	for i := 0; i < 4; i++ {
+		_, span := NewTraceSpan(context.Background(), "exporter-test", fmt.Sprintf("span-%d", i))
+		span.End()
+		// 每个span单独交给导出器
+		if err := tp.ForceFlush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := writer.len(); n != 3 {
+		t.Fatalf("Expected 3 spans written when buffer is full, got %d", n)
+	}
+
+	if err := exporter.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := writer.len(); n != 4 {
+		t.Fatalf("Expected 4 spans written after ForceFlush, got %d", n)
+	}
+}
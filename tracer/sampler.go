@@ -0,0 +1,58 @@
+package tracer
+
+import (
+	"context"
+	"strconv"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DebugTraceHeader 强制采样的请求头，值为 1/true 时GinTraceMiddleware对该请求强制采样
+const DebugTraceHeader = "X-Debug-Trace"
+
+// forceSampleKey 强制采样标记的上下文key
+type forceSampleKey struct{}
+
+// WithForceSample 标记ctx强制采样，之后基于该ctx创建的span都会被采样
+func WithForceSample(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceSampleKey{}, true)
+}
+
+// IsForceSampled 判断ctx是否带有强制采样标记
+func IsForceSampled(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceSampleKey{}).(bool)
+	return forced
+}
+
+// isDebugHeader 判断调试请求头的值是否开启
+func isDebugHeader(value string) bool {
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// debugSampler 带强制采样标记时采样，否则交给base决定
+type debugSampler struct {
+	base sdktrace.Sampler
+}
+
+// DebugSampler 包装采样器，ctx带有WithForceSample标记时忽略base的采样率直接采样
+func DebugSampler(base sdktrace.Sampler) sdktrace.Sampler {
+	return debugSampler{base: base}
+}
+
+// ShouldSample 实现sdktrace.Sampler接口
+func (s debugSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if IsForceSampled(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+// Description 实现sdktrace.Sampler接口
+func (s debugSampler) Description() string {
+	return "DebugSampler{" + s.base.Description() + "}"
+}
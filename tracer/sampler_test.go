@@ -0,0 +1,61 @@
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestDebugHeaderForcesSampling 测试采样率为0时，带调试请求头的请求仍然被采样
+func TestDebugHeaderForcesSampling(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(DebugSampler(sdktrace.TraceIDRatioBased(0))),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(GinTraceMiddleware())
+	var childSampled bool
+	router.GET("/ping", func(c *gin.Context) {
+		_, child := NewTraceSpan(c.Request.Context(), "test", "child")
+		childSampled = child.SpanContext().IsSampled()
+		child.End()
+		c.String(http.StatusOK, "pong")
+	})
+
+	// 未携带调试请求头，不采样
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if n := len(recorder.Ended()); n != 0 {
+		t.Fatalf("Expected no sampled spans at ratio 0, got %d", n)
+	}
+
+	// 携带调试请求头，请求span及子span均被采样
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(DebugTraceHeader, "1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 sampled spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if !span.SpanContext().IsSampled() {
+			t.Errorf("Expected span %s to be sampled", span.Name())
+		}
+	}
+	if !childSampled {
+		t.Error("Expected child span to be sampled")
+	}
+	if spans[0].SpanContext().TraceID() != spans[1].SpanContext().TraceID() {
+		t.Error("Expected child span to share the request trace")
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lwy110193/go_vendor/ctxkey"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -26,14 +27,27 @@ import (
 // logger: 当loggerType为LoggerTypeLocal时，必须传入有效的local log实例
 // 返回: 清理函数
 func InitTracer(serviceName string, exporter sdktrace.SpanExporter) func() {
+	return initTracer(serviceName, exporter)
+}
+
+// InitTracerWithSampler 初始化tracer并指定采样器，如 sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.1))
+// 采样器会被DebugSampler包装，携带调试标记的请求不受采样率限制
+// 返回: 清理函数
+func InitTracerWithSampler(serviceName string, exporter sdktrace.SpanExporter, sampler sdktrace.Sampler) func() {
+	return initTracer(serviceName, exporter, sdktrace.WithSampler(DebugSampler(sampler)))
+}
+
+// initTracer 创建并设置全局trace provider
+func initTracer(serviceName string, exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) func() {
 	// 创建trace provider
-	tp := sdktrace.NewTracerProvider(
+	opts = append([]sdktrace.TracerProviderOption{
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(resource.NewWithAttributes(
 			semconv.SchemaURL,
 			semconv.ServiceNameKey.String(serviceName),
 		)),
-	)
+	}, opts...)
+	tp := sdktrace.NewTracerProvider(opts...)
 
 	// 设置全局trace provider
 	otel.SetTracerProvider(tp)
@@ -78,16 +92,14 @@ func NewSpan(ctx context.Context, tracer trace.Tracer, spanName string) (context
 // NewSpanWithCtx 创建一个新的span，使用上下文中的traceID
 // ctx: 父上下文
 // spanName: span名称
-// ctxTraceIdKey: 上下文key，用于存储traceID
-// 返回: 新的上下文、span实例
+// ctxTraceIdKey: 兼容旧代码的字符串上下文key，优先读取ctxkey.TraceID，不存在时再按该key读取
+// 返回: 新的上下文（已通过ctxkey写入traceID）、span实例
 func NewSpanWithCtx(ctx context.Context, traceName, spanName, ctxTraceIdKey string) (context.Context, trace.Span) {
-	var traceID string
-	if ctx.Value(ctxTraceIdKey) != nil {
-		traceID = ctx.Value(ctxTraceIdKey).(string)
-		if len(traceID) != 32 {
-			traceID = strings.ReplaceAll(uuid.New().String(), "-", "")
-		}
-	} else {
+	traceID, ok := ctxkey.TraceID(ctx)
+	if !ok {
+		traceID, _ = ctxkey.LegacyString(ctx, ctxTraceIdKey)
+	}
+	if len(traceID) != 32 {
 		traceID = strings.ReplaceAll(uuid.New().String(), "-", "")
 	}
 
@@ -106,6 +118,7 @@ func NewSpanWithCtx(ctx context.Context, traceName, spanName, ctxTraceIdKey stri
 
 	// 创建关联新 TraceID 的 Span
 	ctx = trace.ContextWithRemoteSpanContext(ctx, newConfig)
+	ctx = ctxkey.WithTraceID(ctx, traceID)
 	_, span := tracer.Start(ctx, spanName)
 	// defer span.End()
 
@@ -116,10 +129,17 @@ func NewSpanWithCtx(ctx context.Context, traceName, spanName, ctxTraceIdKey stri
 func GinTraceMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ginCtx := c.Request.Context()
+		if isDebugHeader(c.GetHeader(DebugTraceHeader)) {
+			ginCtx = WithForceSample(ginCtx)
+		}
 		tracer := otel.Tracer("__TRACE__" + c.FullPath())
 		spanName := "__SPAN__" + c.FullPath()
 		ctx, span := tracer.Start(ginCtx, spanName)
 		defer span.End()
+		ctx = ctxkey.WithTraceID(ctx, span.SpanContext().TraceID().String())
+		if requestID := c.GetHeader("X-Request-ID"); requestID != "" {
+			ctx = ctxkey.WithRequestID(ctx, requestID)
+		}
 		c.Request = c.Request.WithContext(ctx)
 
 		span.SetAttributes(semconv.HTTPRouteKey.String(c.FullPath()))
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrFlusherClosed BatchFlusher关闭后继续添加数据时返回的错误
+var ErrFlusherClosed = errors.New("batch flusher closed")
+
+// BatchFlusher 批量缓冲写入器，数据条数达到maxItems或距上次写入超过maxInterval时调用flush
+// 常用于聚合数据库插入，如配合BaseRepo.CreateBatch；flush调用是串行的，批次顺序与添加顺序一致
+type BatchFlusher[T any] struct {
+	mu       sync.Mutex
+	buf      []T
+	maxItems int
+	flush    func(batch []T) error
+	errs     []error // 定时写入产生的错误，Close时返回
+	closed   bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewBatchFlusher 创建批量缓冲写入器
+// maxItems<=0时不按条数写入；maxInterval<=0时不按时间写入
+func NewBatchFlusher[T any](maxItems int, maxInterval time.Duration, flush func(batch []T) error) *BatchFlusher[T] {
+	b := &BatchFlusher[T]{
+		maxItems: maxItems,
+		flush:    flush,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if maxInterval <= 0 {
+		close(b.done)
+		return b
+	}
+
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(maxInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				b.mu.Lock()
+				if err := b.flushLocked(); err != nil {
+					b.errs = append(b.errs, err)
+				}
+				b.mu.Unlock()
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+	return b
+}
+
+// Add 添加数据，达到maxItems时同步写入并返回写入错误
+// 写入期间其他Add会阻塞，形成背压
+func (b *BatchFlusher[T]) Add(items ...T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrFlusherClosed
+	}
+	b.buf = append(b.buf, items...)
+	if b.maxItems > 0 && len(b.buf) >= b.maxItems {
+		return b.flushLocked()
+	}
+	return nil
+}
+
+// Flush 立即写入缓冲中的数据
+func (b *BatchFlusher[T]) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked()
+}
+
+// flushLocked 写入缓冲中的数据，调用方需持有锁
+// 写入失败时该批数据被丢弃，由flush自行决定是否重试或记录
+func (b *BatchFlusher[T]) flushLocked() error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	batch := b.buf
+	b.buf = nil
+	return b.flush(batch)
+}
+
+// Close 停止定时写入并写入剩余数据，返回定时写入期间的错误与最后一次写入的错误
+// 重复调用是安全的
+func (b *BatchFlusher[T]) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	errs := append(b.errs, b.flushLocked())
+	b.errs = nil
+	return errors.Join(errs...)
+}
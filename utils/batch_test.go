@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/utils"
+)
+
+// batchRecorder 记录每次写入的批次
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *batchRecorder) flush(batch []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func TestBatchFlusherSize(t *testing.T) {
+	rec := &batchRecorder{}
+	flusher := utils.NewBatchFlusher(3, time.Hour, rec.flush)
+
+	for i := 0; i < 7; i++ {
+		if err := flusher.Add(i); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if got := rec.sizes(); len(got) != 2 || got[0] != 3 || got[1] != 3 {
+		t.Errorf("batch sizes before Close = %v, want [3 3]", got)
+	}
+
+	if err := flusher.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := rec.sizes(); len(got) != 3 || got[2] != 1 {
+		t.Errorf("batch sizes after Close = %v, want [3 3 1]", got)
+	}
+	if rec.batches[2][0] != 6 {
+		t.Errorf("last batch = %v, want [6]", rec.batches[2])
+	}
+	if err := flusher.Add(7); !errors.Is(err, utils.ErrFlusherClosed) {
+		t.Errorf("Add() after Close error = %v, want ErrFlusherClosed", err)
+	}
+}
+
+func TestBatchFlusherInterval(t *testing.T) {
+	rec := &batchRecorder{}
+	flusher := utils.NewBatchFlusher(100, 20*time.Millisecond, rec.flush)
+	defer flusher.Close()
+
+	flusher.Add(1, 2)
+
+	deadline := time.Now().Add(time.Second)
+	for len(rec.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := rec.sizes(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("batch sizes = %v, want [2]", got)
+	}
+}
+
+func TestBatchFlusherCloseReturnsErrors(t *testing.T) {
+	errFlush := errors.New("flush failed")
+	flusher := utils.NewBatchFlusher(0, 10*time.Millisecond, func([]string) error { return errFlush })
+
+	flusher.Add("a")
+	time.Sleep(50 * time.Millisecond)
+	flusher.Add("b")
+
+	if err := flusher.Close(); !errors.Is(err, errFlush) {
+		t.Errorf("Close() error = %v, want errFlush", err)
+	}
+}
@@ -0,0 +1,25 @@
+package utils
+
+// Coalesce 返回第一个非零值，全部为零值时返回类型零值
+// 常用于配置合并：utils.Coalesce(cfg.Encoding, envEncoding, "json")
+func Coalesce[T comparable](vals ...T) T {
+	var zero T
+	for _, val := range vals {
+		if val != zero {
+			return val
+		}
+	}
+	return zero
+}
+
+// CoalesceFunc 返回第一个isEmpty判断为非空的值，适用于切片、map等不可比较的类型
+// 全部为空时返回类型零值
+func CoalesceFunc[T any](isEmpty func(T) bool, vals ...T) T {
+	for _, val := range vals {
+		if !isEmpty(val) {
+			return val
+		}
+	}
+	var zero T
+	return zero
+}
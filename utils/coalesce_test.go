@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/lwy110193/go_vendor/utils"
+)
+
+func TestCoalesce(t *testing.T) {
+	if got := utils.Coalesce("", "", "json", "console"); got != "json" {
+		t.Errorf("Coalesce() = %q, want json", got)
+	}
+	if got := utils.Coalesce("", ""); got != "" {
+		t.Errorf("Coalesce() = %q, want empty", got)
+	}
+	if got := utils.Coalesce(0, 100, 7); got != 100 {
+		t.Errorf("Coalesce() = %d, want 100", got)
+	}
+	if got := utils.Coalesce[int](); got != 0 {
+		t.Errorf("Coalesce() = %d, want 0", got)
+	}
+}
+
+func TestCoalesceFunc(t *testing.T) {
+	isEmpty := func(list []string) bool { return len(list) == 0 }
+
+	got := utils.CoalesceFunc(isEmpty, nil, []string{}, []string{"a", "b"})
+	if len(got) != 2 || got[0] != "a" {
+		t.Errorf("CoalesceFunc() = %v, want [a b]", got)
+	}
+	if got = utils.CoalesceFunc(isEmpty, nil, []string{}); got != nil {
+		t.Errorf("CoalesceFunc() = %v, want nil", got)
+	}
+
+	// 自定义判断：非正数视为未设置
+	notPositive := func(n int) bool { return n <= 0 }
+	if got := utils.CoalesceFunc(notPositive, -1, 0, 50); got != 50 {
+		t.Errorf("CoalesceFunc() = %d, want 50", got)
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// binaryUnits FormatBytes使用的二进制单位，依次相差1024倍
+var binaryUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// byteUnits ParseBytes支持的单位（小写）
+// KiB、Ki、K等二进制单位按1024进位，KB、MB等十进制单位按1000进位
+var byteUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "ki": 1 << 10, "kib": 1 << 10, "kb": 1e3,
+	"m": 1 << 20, "mi": 1 << 20, "mib": 1 << 20, "mb": 1e6,
+	"g": 1 << 30, "gi": 1 << 30, "gib": 1 << 30, "gb": 1e9,
+	"t": 1 << 40, "ti": 1 << 40, "tib": 1 << 40, "tb": 1e12,
+	"p": 1 << 50, "pi": 1 << 50, "pib": 1 << 50, "pb": 1e15,
+	"e": 1 << 60, "ei": 1 << 60, "eib": 1 << 60, "eb": 1e18,
+}
+
+// FormatBytes 将字节数格式化为便于阅读的字符串，如 "512 B"、"1.5 MiB"
+// 使用1024进位的二进制单位，保留一位小数，整数时省略小数部分
+func FormatBytes(n int64) string {
+	sign := ""
+	v := float64(n)
+	if n < 0 {
+		sign, v = "-", -v
+	}
+	unit := 0
+	for v >= 1024 && unit < len(binaryUnits)-1 {
+		v /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%s%d %s", sign, int64(v), binaryUnits[0])
+	}
+	return sign + formatFloat(v) + " " + binaryUnits[unit]
+}
+
+// ParseBytes 解析带单位的字节数，如 "1.5MiB"、"10 KB"、"512"，单位不区分大小写
+// KiB/Ki/K为1024进位，KB为1000进位，无单位时按字节处理
+func ParseBytes(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(str)
+	}
+	numStr, unitStr := str[:i], strings.ToLower(strings.TrimSpace(str[i:]))
+	if numStr == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	num, err := strconv.ParseFloat(numStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q: %w", s, err)
+	}
+	multiplier, ok := byteUnits[unitStr]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, str[i:])
+	}
+	bytes := num * multiplier
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q: value out of range", s)
+	}
+	return int64(bytes), nil
+}
+
+// FormatDuration 将时长格式化为紧凑的字符串，最多保留两个单位，如 "3d4h"、"1h2m"、"1.5s"、"250ms"
+// 与time.Duration.String相比不会输出 "1h2m3.456789s" 这类冗长的形式
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	const day = 24 * time.Hour
+	switch {
+	case d >= day:
+		return joinUnits(int64(d/day), "d", int64(d%day/time.Hour), "h")
+	case d >= time.Hour:
+		return joinUnits(int64(d/time.Hour), "h", int64(d%time.Hour/time.Minute), "m")
+	case d >= time.Minute:
+		return joinUnits(int64(d/time.Minute), "m", int64(d%time.Minute/time.Second), "s")
+	case d >= time.Second:
+		return formatFloat(d.Seconds()) + "s"
+	case d >= time.Millisecond:
+		return formatFloat(float64(d)/float64(time.Millisecond)) + "ms"
+	case d >= time.Microsecond:
+		return formatFloat(float64(d)/float64(time.Microsecond)) + "µs"
+	default:
+		return strconv.FormatInt(int64(d), 10) + "ns"
+	}
+}
+
+// joinUnits 拼接两个单位，次单位为0时省略
+func joinUnits(major int64, majorUnit string, minor int64, minorUnit string) string {
+	s := strconv.FormatInt(major, 10) + majorUnit
+	if minor > 0 {
+		s += strconv.FormatInt(minor, 10) + minorUnit
+	}
+	return s
+}
+
+// formatFloat 保留一位小数（截断，避免进位后出现 "1024 KiB"），小数部分为0时省略
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(math.Floor(v*10+1e-9)/10, 'f', -1, 64)
+}
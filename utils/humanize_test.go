@@ -0,0 +1,100 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/utils"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1023, "1023 B"},
+		{1024, "1 KiB"},
+		{1536, "1.5 KiB"},
+		{1024*1024 - 1, "1023.9 KiB"},
+		{3 << 19, "1.5 MiB"},
+		{1363149, "1.3 MiB"},
+		{5 << 30, "5 GiB"},
+		{3 << 39, "1.5 TiB"},
+		{1 << 50, "1 PiB"},
+		{1 << 62, "4 EiB"},
+		{-1536, "-1.5 KiB"},
+	}
+	for _, tt := range tests {
+		if got := utils.FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"1KiB", 1024},
+		{"1.5MiB", 3 << 19},
+		{"1.5 MiB", 3 << 19},
+		{" 10 mb ", 10e6},
+		{"10M", 10 << 20},
+		{"2Gi", 2 << 30},
+		{"1GB", 1e9},
+		{"1TiB", 1 << 40},
+		{"1pb", 1e15},
+		{"4EiB", 1 << 62},
+	}
+	for _, tt := range tests {
+		got, err := utils.ParseBytes(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v, want %d", tt.s, got, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "MiB", "-1KiB", "1.2.3KiB", "10XB", "8EiB"} {
+		if got, err := utils.ParseBytes(s); err == nil {
+			t.Errorf("ParseBytes(%q) = %d, want error", s, got)
+		}
+	}
+
+	// 格式化结果可以被解析回来
+	for _, n := range []int64{1024, 3 << 19, 5 << 30} {
+		if got, err := utils.ParseBytes(utils.FormatBytes(n)); err != nil || got != n {
+			t.Errorf("ParseBytes(FormatBytes(%d)) = %d, %v", n, got, err)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0ns"},
+		{500 * time.Nanosecond, "500ns"},
+		{1500 * time.Nanosecond, "1.5µs"},
+		{250 * time.Millisecond, "250ms"},
+		{1234567 * time.Nanosecond, "1.2ms"},
+		{time.Second, "1s"},
+		{1500 * time.Millisecond, "1.5s"},
+		{59*time.Second + 999*time.Millisecond, "59.9s"},
+		{time.Minute, "1m"},
+		{2*time.Minute + 3*time.Second + 456*time.Millisecond, "2m3s"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1h2m"},
+		{24 * time.Hour, "1d"},
+		{76*time.Hour + 30*time.Minute, "3d4h"},
+		{-1500 * time.Millisecond, "-1.5s"},
+	}
+	for _, tt := range tests {
+		if got := utils.FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError 由panic转换而来的错误，包含panic的值与发生时的调用栈
+type PanicError struct {
+	// Value recover()得到的值
+	Value interface{}
+	// Stack panic发生时的调用栈
+	Stack []byte
+}
+
+// Error 实现error接口，包含panic值与调用栈
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap panic的值为error时返回该error，便于errors.Is/As判断
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// RecoverToError 将recover()的结果转换为带调用栈的错误，r为nil时返回nil
+// 需在defer函数中直接调用，才能捕获到panic发生位置的调用栈：
+//
+//	defer func() {
+//		if err := utils.RecoverToError(recover()); err != nil {
+//			// 处理错误
+//		}
+//	}()
+func RecoverToError(r interface{}) error {
+	if r == nil {
+		return nil
+	}
+	return &PanicError{Value: r, Stack: debug.Stack()}
+}
+
+// SafeGo 在新的协程中执行fn并捕获panic
+// 返回的通道在fn结束后收到一个结果(正常结束为nil，panic时为*PanicError)，随后关闭
+func SafeGo(fn func()) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		defer func() {
+			errCh <- RecoverToError(recover())
+		}()
+		fn()
+	}()
+	return errCh
+}
+
+// IsPanicError 判断错误是否由panic转换而来
+func IsPanicError(err error) bool {
+	var panicErr *PanicError
+	return errors.As(err, &panicErr)
+}
@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lwy110193/go_vendor/utils"
+)
+
+func panicInner() {
+	panic("something wrong")
+}
+
+func TestRecoverToError(t *testing.T) {
+	if err := utils.RecoverToError(nil); err != nil {
+		t.Errorf("RecoverToError(nil) = %v, want nil", err)
+	}
+
+	var err error
+	func() {
+		defer func() {
+			err = utils.RecoverToError(recover())
+		}()
+		panicInner()
+	}()
+
+	var panicErr *utils.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("RecoverToError() = %T, want *utils.PanicError", err)
+	}
+	if panicErr.Value != "something wrong" {
+		t.Errorf("Value = %v, want something wrong", panicErr.Value)
+	}
+	// 调用栈包含panic发生的函数
+	if !strings.Contains(string(panicErr.Stack), "panicInner") {
+		t.Errorf("Stack does not contain panicInner:\n%s", panicErr.Stack)
+	}
+	if !strings.Contains(err.Error(), "panic: something wrong") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestSafeGo(t *testing.T) {
+	if err := <-utils.SafeGo(func() {}); err != nil {
+		t.Errorf("SafeGo() = %v, want nil", err)
+	}
+
+	errTarget := errors.New("target")
+	err := <-utils.SafeGo(func() { panic(errTarget) })
+	if !utils.IsPanicError(err) {
+		t.Fatalf("SafeGo() = %v, want panic error", err)
+	}
+	if !errors.Is(err, errTarget) {
+		t.Errorf("errors.Is(err, target) = false")
+	}
+	if !strings.Contains(err.Error(), "recover_test.go") {
+		t.Errorf("Error() does not contain stack: %s", err.Error())
+	}
+}
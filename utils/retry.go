@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// Retry 执行fn，失败时按指数退避重试，最多执行attempts次（attempts小于1时按1次处理）
+// retryable为nil时所有错误都重试；retryable返回false的错误立即返回
+// 等待期间ctx结束则停止重试，返回最后一次的错误
+func Retry(ctx context.Context, attempts int, backoff time.Duration, retryable func(error) bool, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if retryable != nil && !retryable(err) {
+			return err
+		}
+		if i == attempts-1 {
+			break
+		}
+
+		timer := time.NewTimer(backoff << i)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
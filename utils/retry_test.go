@@ -0,0 +1,67 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/utils"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := utils.Retry(context.Background(), 3, time.Millisecond, nil, func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Retry() = %v after %d calls, want nil after 3", err, calls)
+	}
+
+	calls = 0
+	err = utils.Retry(context.Background(), 2, time.Millisecond, nil, func() error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) || calls != 2 {
+		t.Errorf("Retry() = %v after %d calls, want errTransient after 2", err, calls)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	permanent := errors.New("permanent")
+	calls := 0
+	err := utils.Retry(context.Background(), 5, time.Millisecond, func(err error) bool {
+		return errors.Is(err, errTransient)
+	}, func() error {
+		calls++
+		return permanent
+	})
+	if !errors.Is(err, permanent) || calls != 1 {
+		t.Errorf("Retry() = %v after %d calls, want permanent after 1", err, calls)
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := utils.Retry(ctx, 5, time.Second, nil, func() error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) || calls != 1 {
+		t.Errorf("Retry() = %v after %d calls, want errTransient after 1", err, calls)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Retry() did not stop waiting when ctx was done")
+	}
+}
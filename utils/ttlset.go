@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLSet 带过期时间的字符串集合，用于消息ID去重等"近期是否处理过"的判断
+// 比完整的缓存更轻量，只记录键是否存在；过期的键由后台协程定期清理
+type TTLSet struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	items    map[string]time.Time // 键 -> 过期时刻
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewTTLSet 创建集合，键在加入ttl之后过期，后台每隔ttl清理一次过期的键，ttl必须大于0
+// 不再使用时需调用Close停止后台协程
+func NewTTLSet(ttl time.Duration) *TTLSet {
+	s := &TTLSet{
+		ttl:   ttl,
+		items: make(map[string]time.Time),
+		stop:  make(chan struct{}),
+	}
+	go s.cleanupLoop()
+	return s
+}
+
+// AddIfAbsent 键不存在或已过期时加入并返回true；键在有效期内已存在时返回false，且不延长其过期时间
+func (s *TTLSet) AddIfAbsent(key string) bool {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expiry, ok := s.items[key]; ok && now.Before(expiry) {
+		return false
+	}
+	s.items[key] = now.Add(s.ttl)
+	return true
+}
+
+// Contains 判断键是否在有效期内
+func (s *TTLSet) Contains(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiry, ok := s.items[key]
+	return ok && time.Now().Before(expiry)
+}
+
+// Len 返回集合中的键数量，可能包含尚未清理的过期键
+func (s *TTLSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// Close 停止后台清理，重复调用是安全的
+func (s *TTLSet) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// cleanupLoop 定期删除过期的键
+func (s *TTLSet) cleanupLoop() {
+	ticker := time.NewTicker(s.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			s.mu.Lock()
+			for key, expiry := range s.items {
+				if !now.Before(expiry) {
+					delete(s.items, key)
+				}
+			}
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
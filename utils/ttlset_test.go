@@ -0,0 +1,32 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lwy110193/go_vendor/utils"
+)
+
+func TestTTLSet(t *testing.T) {
+	set := utils.NewTTLSet(50 * time.Millisecond)
+	defer set.Close()
+
+	if !set.AddIfAbsent("msg-1") {
+		t.Fatal("first AddIfAbsent should report new")
+	}
+	if set.AddIfAbsent("msg-1") {
+		t.Fatal("second AddIfAbsent should report seen")
+	}
+	if !set.Contains("msg-1") || set.Contains("msg-2") {
+		t.Fatal("Contains() mismatch")
+	}
+
+	// 过期后再次视为新键，后台清理后不再占用内存
+	time.Sleep(120 * time.Millisecond)
+	if set.Len() != 0 {
+		t.Errorf("Len() = %d after expiry, want 0", set.Len())
+	}
+	if !set.AddIfAbsent("msg-1") {
+		t.Error("AddIfAbsent after expiry should report new")
+	}
+}